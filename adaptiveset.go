@@ -0,0 +1,652 @@
+package intset
+
+import (
+	"iter"
+)
+
+// Representation is the kind of set an AdaptiveSet stores its integers in.
+type Representation int
+
+// The representations of an AdaptiveSet.
+const (
+	// ArrayRepresentation stores the integers in an ArraySet.
+	ArrayRepresentation Representation = iota
+	// HashRepresentation stores the integers in a HashSet.
+	HashRepresentation
+	// BitmapRepresentation stores the integers in a BitSet.
+	BitmapRepresentation
+	// RunsRepresentation stores the integers in an IntervalSet.
+	RunsRepresentation
+)
+
+// String returns the name of the representation.
+func (r Representation) String() string {
+	switch r {
+	case ArrayRepresentation:
+		return "array"
+	case HashRepresentation:
+		return "hash"
+	case BitmapRepresentation:
+		return "bitmap"
+	case RunsRepresentation:
+		return "runs"
+	}
+	return "unknown"
+}
+
+// AdaptiveThresholds are the conversion points of an AdaptiveSet.
+type AdaptiveThresholds struct {
+	// ArraySize is the largest number of integers stored as a sorted array.
+	ArraySize int
+
+	// BitmapDensity is the smallest ratio of the number of integers to the
+	// span of the set, from its smallest to its largest integer, for the
+	// integers to be stored as a bitmap rather than in a hash set.
+	BitmapDensity float64
+
+	// RunLength is the smallest average length of the runs of consecutive
+	// integers for the integers to be stored as runs. Zero disables the runs
+	// representation.
+	RunLength float64
+}
+
+// DefaultAdaptiveThresholds are the thresholds of a new AdaptiveSet.
+var DefaultAdaptiveThresholds = AdaptiveThresholds{
+	ArraySize:     4096,
+	BitmapDensity: 1.0 / 64,
+	RunLength:     64,
+}
+
+// AdaptiveSet is an integer set which converts itself between representations
+// as its integers change, so that its shape need not be known up front. It
+// starts as a sorted array, and is stored once it grows:
+//
+//   - as runs, if the integers form long runs of consecutive integers;
+//   - as a sorted array, if there are few of them;
+//   - as a bitmap, if they are dense enough;
+//   - in a hash set otherwise.
+//
+// The representation is chosen again each time the size of the set has
+// doubled or halved since the previous choice, so the cost of the conversions
+// is amortized over the modifications. It is also chosen again as soon as a
+// sorted array grows too large or runs become too fragmented, and a range or a
+// set which would make a sorted array, a hash set or a bitmap expensive is
+// added to runs instead.
+type AdaptiveSet[T Integer] struct {
+	data       Set[T]
+	size       int
+	checked    int // the size at the last choice of representation
+	thresholds AdaptiveThresholds
+}
+
+// NewAdaptiveSet is the constructor for an AdaptiveSet of ints. Max is ignored
+// in this set implementation.
+func NewAdaptiveSet(max int) *AdaptiveSet[int] {
+	return NewAdaptiveSetOf[int](max)
+}
+
+// NewAdaptiveSetOf is the constructor for an AdaptiveSet of any integer type.
+// Max is ignored in this set implementation.
+func NewAdaptiveSetOf[T Integer](max int) *AdaptiveSet[T] {
+	return new(AdaptiveSet[T]).init(max)
+}
+
+func (set *AdaptiveSet[T]) init(max int) *AdaptiveSet[T] {
+	set.data = NewArraySetOf[T](max)
+	set.size = 0
+	set.checked = 0
+	set.thresholds = DefaultAdaptiveThresholds
+	return set
+}
+
+// Tune sets the conversion points of the set, and converts it if its current
+// representation does not suit them.
+func (set *AdaptiveSet[T]) Tune(t AdaptiveThresholds) *AdaptiveSet[T] {
+	set.thresholds = t
+	set.adapt()
+	return set
+}
+
+// Representation returns the representation the set currently stores its
+// integers in.
+func (set *AdaptiveSet[T]) Representation() Representation {
+	switch set.data.(type) {
+	case *ArraySet[T]:
+		return ArrayRepresentation
+	case *BitSet[T]:
+		return BitmapRepresentation
+	case *IntervalSet[T]:
+		return RunsRepresentation
+	}
+	return HashRepresentation
+}
+
+// convert stores the integers of the set in the representation r.
+func (set *AdaptiveSet[T]) convert(r Representation) {
+	switch r {
+	case ArrayRepresentation:
+		set.data = NewArraySetOf[T](0).UnionWith(set.data)
+	case HashRepresentation:
+		set.data = NewHashSetOf[T](0).UnionWith(set.data)
+	case BitmapRepresentation:
+		set.data = NewBitSetOf[T](0).UnionWith(set.data)
+	case RunsRepresentation:
+		set.data = NewIntervalSetOf[T](0).UnionWith(set.data)
+	}
+}
+
+// adapt converts the set to the representation which suits it best.
+func (set *AdaptiveSet[T]) adapt() {
+	set.checked = set.size
+	if r := set.choose(); r != set.Representation() {
+		set.convert(r)
+	}
+}
+
+// changed is called after the set is modified. It chooses the representation
+// again if the size has doubled or halved since the last choice, if a sorted
+// array has grown too large, or if runs have become too fragmented.
+func (set *AdaptiveSet[T]) changed() {
+	n, t := set.size, set.thresholds
+	switch s := set.data.(type) {
+	case *ArraySet[T]:
+		if n > t.ArraySize {
+			set.adapt()
+			return
+		}
+	case *IntervalSet[T]:
+		if float64(n) < t.RunLength/2*float64(len(s.runs)) {
+			set.adapt()
+			return
+		}
+	}
+	if n > 2*set.checked || n < set.checked/2 {
+		set.adapt()
+	}
+}
+
+// choose returns the representation which suits the set best.
+func (set *AdaptiveSet[T]) choose() Representation {
+	n, t := set.size, set.thresholds
+	if n == 0 {
+		return ArrayRepresentation
+	}
+	// the bounds are read once, as they take a scan of a hash set
+	lo, _ := set.data.Min()
+	hi, _ := set.data.Max()
+	if t.RunLength > 0 {
+		limit := int(float64(n) / t.RunLength)
+		if set.countRuns(lo, limit) <= limit {
+			return RunsRepresentation
+		}
+	}
+	if n <= t.ArraySize {
+		return ArrayRepresentation
+	}
+	i, iok := toInt(lo)
+	j, jok := toInt(hi)
+	if iok && jok && dense(i, j, n, t.BitmapDensity) {
+		return BitmapRepresentation
+	}
+	return HashRepresentation
+}
+
+// countRuns returns the number of runs of consecutive integers in the set,
+// whose smallest integer is lo, or limit+1 if there are more than limit.
+func (set *AdaptiveSet[T]) countRuns(lo T, limit int) int {
+	if s, ok := set.data.(*IntervalSet[T]); ok {
+		return len(s.runs)
+	}
+	_, sorted := set.data.(ordered[T])
+	var n int
+	var prev T
+	for v := range set.data.Values() {
+		if v == lo || (sorted && prev+1 != v) || (!sorted && !set.data.Contains(v-1)) {
+			if n++; n > limit {
+				break
+			}
+		}
+		prev = v
+	}
+	return n
+}
+
+// dense returns true if a bitmap spanning the integers from lo to hi, holding
+// n integers, would be at least as dense as density.
+func dense(lo, hi, n int, density float64) bool {
+	return float64(n) >= density*(float64(uint(hi)-uint(lo))+1)
+}
+
+// denseWith returns true if the bitmap b, grown to hold the integers from lo to
+// hi, and holding n integers, would be at least as dense as
+// density. The span of the bitmap is taken from its words, so that no
+// integers are scanned. It returns false if lo or hi does not fit in an int.
+func denseWith[T Integer](b *BitSet[T], lo, hi T, n int, density float64) bool {
+	i, iok := toInt(lo)
+	j, jok := toInt(hi)
+	if !iok || !jok {
+		return false
+	}
+	if len(b.words) > 0 {
+		i, j = min(i, b.base*64), max(j, (b.end()-1)*64+63)
+	}
+	return dense(i, j, n, density)
+}
+
+// reserve converts the set, if needed, before the integers from lo to hi, or
+// n of them if ranged is false, are added to it. A range of more than
+// ArraySize integers is added to runs rather than one integer at a time, and a
+// bitmap which would become less than half as dense as BitmapDensity is
+// converted to a hash set.
+func (set *AdaptiveSet[T]) reserve(lo, hi T, n int, ranged bool) {
+	t := set.thresholds
+	b, bitmap := set.data.(*BitSet[T])
+	switch r := set.Representation(); {
+	case r == RunsRepresentation:
+	case bitmap && denseWith(b, lo, hi, set.size+n, t.BitmapDensity/2):
+	case ranged && n > t.ArraySize:
+		set.convert(RunsRepresentation)
+	case r == BitmapRepresentation:
+		set.convert(HashRepresentation)
+	}
+}
+
+// Clear the set.
+func (set *AdaptiveSet[T]) Clear() *AdaptiveSet[T] {
+	set.data = NewArraySetOf[T](0)
+	set.size = 0
+	set.checked = 0
+	return set
+}
+
+// Size returns the number of integers in the set.
+func (set *AdaptiveSet[T]) Size() int {
+	return set.size
+}
+
+// Add one or more integers to the set.
+func (set *AdaptiveSet[T]) Add(ints ...T) *AdaptiveSet[T] {
+	for _, v := range ints {
+		if set.data.Contains(v) {
+			continue
+		}
+		if b, ok := set.data.(*BitSet[T]); ok {
+			if k, _, ok := bitPos(v); !ok || k < b.base || k >= b.end() {
+				set.reserve(v, v, 1, false)
+			}
+		}
+		set.data.Insert(v)
+		set.size++
+	}
+	set.changed()
+	return set
+}
+
+// Remove one or more integers from the set.
+func (set *AdaptiveSet[T]) Remove(ints ...T) *AdaptiveSet[T] {
+	for _, v := range ints {
+		if set.data.Contains(v) {
+			set.data.Delete(v)
+			set.size--
+		}
+	}
+	set.changed()
+	return set
+}
+
+// Range operations of rangeOf.
+const (
+	opAddRange = iota
+	opRemoveRange
+	opFlipRange
+)
+
+// ranger is implemented by the set types with range operations, where S is the
+// set type itself.
+type ranger[T Integer, S any] interface {
+	AddRange(lo, hi T) S
+	RemoveRange(lo, hi T) S
+	FlipRange(lo, hi T) S
+}
+
+func rangeOf[S ranger[T, S], T Integer](s S, op int, lo, hi T) {
+	switch op {
+	case opAddRange:
+		s.AddRange(lo, hi)
+	case opRemoveRange:
+		s.RemoveRange(lo, hi)
+	case opFlipRange:
+		s.FlipRange(lo, hi)
+	}
+}
+
+// applyRange applies the range operation op to the integers from lo to hi.
+func (set *AdaptiveSet[T]) applyRange(op int, lo, hi T) *AdaptiveSet[T] {
+	if hi < lo {
+		return set
+	}
+	if op != opRemoveRange {
+		set.reserve(lo, hi, Interval[T]{lo, hi}.size(), true)
+	}
+	switch s := set.data.(type) {
+	case *ArraySet[T]:
+		rangeOf(s, op, lo, hi)
+	case *BitSet[T]:
+		rangeOf(s, op, lo, hi)
+	case *HashSet[T]:
+		rangeOf(s, op, lo, hi)
+	case *IntervalSet[T]:
+		rangeOf(s, op, lo, hi)
+	}
+	set.size = set.data.Size()
+	set.changed()
+	return set
+}
+
+// AddRange adds the integers from lo to hi, inclusive, to the set.
+func (set *AdaptiveSet[T]) AddRange(lo, hi T) *AdaptiveSet[T] {
+	return set.applyRange(opAddRange, lo, hi)
+}
+
+// RemoveRange removes the integers from lo to hi, inclusive, from the set.
+func (set *AdaptiveSet[T]) RemoveRange(lo, hi T) *AdaptiveSet[T] {
+	return set.applyRange(opRemoveRange, lo, hi)
+}
+
+// FlipRange adds the integers from lo to hi, inclusive, which are not in the
+// set, and removes those which are.
+func (set *AdaptiveSet[T]) FlipRange(lo, hi T) *AdaptiveSet[T] {
+	return set.applyRange(opFlipRange, lo, hi)
+}
+
+// All returns a slice of all the integers in the set. The integers are in
+// ascending order unless the set is stored in a hash set.
+func (set *AdaptiveSet[T]) All() []T {
+	return set.data.All()
+}
+
+// Values returns an iterator over the integers in the set. The integers are
+// in ascending order unless the set is stored in a hash set.
+func (set *AdaptiveSet[T]) Values() iter.Seq[T] {
+	return set.data.Values()
+}
+
+// Min returns the smallest integer in the set. The second return value is
+// false if the set is empty.
+func (set *AdaptiveSet[T]) Min() (T, bool) {
+	return set.data.Min()
+}
+
+// Max returns the largest integer in the set. The second return value is false
+// if the set is empty.
+func (set *AdaptiveSet[T]) Max() (T, bool) {
+	return set.data.Max()
+}
+
+// NextAfter returns the smallest integer in the set which is greater than v.
+// The second return value is false if there is no such integer.
+func (set *AdaptiveSet[T]) NextAfter(v T) (T, bool) {
+	return set.data.NextAfter(v)
+}
+
+// PrevBefore returns the largest integer in the set which is less than v. The
+// second return value is false if there is no such integer.
+func (set *AdaptiveSet[T]) PrevBefore(v T) (T, bool) {
+	return set.data.PrevBefore(v)
+}
+
+// Contains returns true if all ints are in the set, otherwise false.
+func (set *AdaptiveSet[T]) Contains(ints ...T) bool {
+	return set.data.Contains(ints...)
+}
+
+// unwrapAdaptive returns the set wrapped by other if it is an AdaptiveSet, so
+// that the wrapped sets can be combined natively.
+func unwrapAdaptive[T Integer](other Set[T]) Set[T] {
+	if o, ok := other.(*AdaptiveSet[T]); ok {
+		return o.data
+	}
+	return other
+}
+
+// Equal checks if two sets both contains all the same items.
+func (set *AdaptiveSet[T]) Equal(other Set[T]) bool {
+	return set.data.Equal(unwrapAdaptive(other))
+}
+
+// SubsetOf checks if all items in set are also present in other set.
+func (set *AdaptiveSet[T]) SubsetOf(other Set[T]) bool {
+	return set.data.SubsetOf(unwrapAdaptive(other))
+}
+
+// SupersetOf checks if a set is a superset of another set.
+func (set *AdaptiveSet[T]) SupersetOf(other Set[T]) bool {
+	return set.data.SupersetOf(unwrapAdaptive(other))
+}
+
+// derive returns a new set with the same thresholds as set, which stores its
+// integers in data.
+func (set *AdaptiveSet[T]) derive(data Set[T]) *AdaptiveSet[T] {
+	result := &AdaptiveSet[T]{data: data, size: data.Size(), thresholds: set.thresholds}
+	result.adapt()
+	return result
+}
+
+// Union returns a new set which is the union of two sets.
+func (set *AdaptiveSet[T]) Union(other Set[T]) *AdaptiveSet[T] {
+	return set.Clone().UnionWith(other)
+}
+
+// Intersection returns a new set with integers common to both sets.
+func (set *AdaptiveSet[T]) Intersection(other Set[T]) *AdaptiveSet[T] {
+	return set.derive(set.data.IntersectionSet(unwrapAdaptive(other)))
+}
+
+// Difference returns a new set with the integers in set which are not in other.
+func (set *AdaptiveSet[T]) Difference(other Set[T]) *AdaptiveSet[T] {
+	return set.derive(set.data.DifferenceSet(unwrapAdaptive(other)))
+}
+
+// SymetricDifference returns a new set with the integers in current and other,
+// but not in both.
+func (set *AdaptiveSet[T]) SymetricDifference(other Set[T]) *AdaptiveSet[T] {
+	return set.Clone().SymmetricDifferenceWith(other)
+}
+
+// reserveFor converts the set, if needed, before the integers of other are
+// added to it.
+func (set *AdaptiveSet[T]) reserveFor(other Set[T]) {
+	lo, ok := other.Min()
+	if !ok {
+		return
+	}
+	hi, _ := other.Max()
+	_, ranged := other.(*IntervalSet[T])
+	set.reserve(lo, hi, other.Size(), ranged)
+}
+
+// applyWith applies the in-place operation op with other to the wrapped set,
+// converting it first if the integers of other are added to it.
+func (set *AdaptiveSet[T]) applyWith(op int, other Set[T]) *AdaptiveSet[T] {
+	other = unwrapAdaptive(other)
+	if op == opUnionWith || op == opSymmetricDifferenceWith {
+		set.reserveFor(other)
+	}
+	applyWith(set.data, op, other)
+	set.size = set.data.Size()
+	set.changed()
+	return set
+}
+
+// UnionWith adds the integers in other to the set. It is the in-place variant
+// of Union.
+func (set *AdaptiveSet[T]) UnionWith(other Set[T]) *AdaptiveSet[T] {
+	return set.applyWith(opUnionWith, other)
+}
+
+// IntersectWith removes the integers which are not in other from the set. It
+// is the in-place variant of Intersection.
+func (set *AdaptiveSet[T]) IntersectWith(other Set[T]) *AdaptiveSet[T] {
+	return set.applyWith(opIntersectWith, other)
+}
+
+// DifferenceWith removes the integers in other from the set. It is the
+// in-place variant of Difference.
+func (set *AdaptiveSet[T]) DifferenceWith(other Set[T]) *AdaptiveSet[T] {
+	return set.applyWith(opDifferenceWith, other)
+}
+
+// SymmetricDifferenceWith adds the integers in other which are not in the set,
+// and removes those which are. It is the in-place variant of
+// SymetricDifference.
+func (set *AdaptiveSet[T]) SymmetricDifferenceWith(other Set[T]) *AdaptiveSet[T] {
+	return set.applyWith(opSymmetricDifferenceWith, other)
+}
+
+// IntersectionCount returns the number of integers common to both sets,
+// without building the intersection.
+func (set *AdaptiveSet[T]) IntersectionCount(other Set[T]) int {
+	return set.data.IntersectionCount(unwrapAdaptive(other))
+}
+
+// UnionCount returns the number of integers in the union of two sets, without
+// building the union.
+func (set *AdaptiveSet[T]) UnionCount(other Set[T]) int {
+	return set.data.UnionCount(unwrapAdaptive(other))
+}
+
+// DifferenceCount returns the number of integers in set which are not in
+// other, without building the difference.
+func (set *AdaptiveSet[T]) DifferenceCount(other Set[T]) int {
+	return set.data.DifferenceCount(unwrapAdaptive(other))
+}
+
+// SymmetricDifferenceCount returns the number of integers in current and
+// other, but not in both, without building the symmetric difference.
+func (set *AdaptiveSet[T]) SymmetricDifferenceCount(other Set[T]) int {
+	return set.data.SymmetricDifferenceCount(unwrapAdaptive(other))
+}
+
+// Intersects returns true if the sets have at least one integer in common.
+func (set *AdaptiveSet[T]) Intersects(other Set[T]) bool {
+	return set.data.Intersects(unwrapAdaptive(other))
+}
+
+// Clone returns a new set which is a clone of current set.
+func (set *AdaptiveSet[T]) Clone() *AdaptiveSet[T] {
+	return &AdaptiveSet[T]{
+		data:       set.data.CloneSet(),
+		size:       set.size,
+		checked:    set.checked,
+		thresholds: set.thresholds,
+	}
+}
+
+// Insert adds one or more integers to the set. It is the Set adapter for Add.
+func (set *AdaptiveSet[T]) Insert(ints ...T) {
+	set.Add(ints...)
+}
+
+// Delete removes one or more integers from the set. It is the Set adapter
+// for Remove.
+func (set *AdaptiveSet[T]) Delete(ints ...T) {
+	set.Remove(ints...)
+}
+
+// CloneSet is the Set adapter for Clone.
+func (set *AdaptiveSet[T]) CloneSet() Set[T] {
+	return set.Clone()
+}
+
+// UnionSet is the Set adapter for Union.
+func (set *AdaptiveSet[T]) UnionSet(other Set[T]) Set[T] {
+	return set.Union(other)
+}
+
+// IntersectionSet is the Set adapter for Intersection.
+func (set *AdaptiveSet[T]) IntersectionSet(other Set[T]) Set[T] {
+	return set.Intersection(other)
+}
+
+// DifferenceSet is the Set adapter for Difference.
+func (set *AdaptiveSet[T]) DifferenceSet(other Set[T]) Set[T] {
+	return set.Difference(other)
+}
+
+// SymetricDifferenceSet is the Set adapter for SymetricDifference.
+func (set *AdaptiveSet[T]) SymetricDifferenceSet(other Set[T]) Set[T] {
+	return set.SymetricDifference(other)
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (set *AdaptiveSet[T]) MarshalBinary() ([]byte, error) {
+	return marshalBinary(tagAdaptiveSet, set.All()), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface. The
+// data may have been marshalled from any set type.
+func (set *AdaptiveSet[T]) UnmarshalBinary(data []byte) error {
+	ints, err := unmarshalBinary[T](data)
+	if err != nil {
+		return err
+	}
+	return set.load(ints)
+}
+
+// MarshalJSON implements the json.Marshaler interface. The set is marshalled
+// as an array of integers in ascending order.
+func (set *AdaptiveSet[T]) MarshalJSON() ([]byte, error) {
+	return marshalJSON(set.All()), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It accepts an array
+// of integers or a string in the form produced by MarshalText.
+func (set *AdaptiveSet[T]) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, set.load, set.loadRuns)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface. The set is
+// marshalled as a comma separated list of integers and ranges of integers in
+// ascending order, e.g. "1-5,9".
+func (set *AdaptiveSet[T]) MarshalText() ([]byte, error) {
+	return marshalText(set.All()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface. It accepts
+// the form produced by MarshalText as well as the one produced by String.
+func (set *AdaptiveSet[T]) UnmarshalText(text []byte) error {
+	return unmarshalText(text, set.loadRuns)
+}
+
+// load replaces the contents of the set with the given integers, and chooses
+// the representation which suits them.
+func (set *AdaptiveSet[T]) load(ints []T) error {
+	if set.data == nil {
+		set.thresholds = DefaultAdaptiveThresholds
+	}
+	set.data = &ArraySet[T]{data: sortedSet(ints)}
+	set.size = set.data.Size()
+	set.adapt()
+	return nil
+}
+
+// loadRuns replaces the contents of the set with the integers of the given
+// ranges. They are loaded as runs, before the representation is chosen.
+func (set *AdaptiveSet[T]) loadRuns(runs []Interval[T]) error {
+	data := new(IntervalSet[T])
+	if err := data.loadRuns(runs); err != nil {
+		return err
+	}
+	if set.data == nil {
+		set.thresholds = DefaultAdaptiveThresholds
+	}
+	set.data = data
+	set.size = data.Size()
+	set.adapt()
+	return nil
+}
+
+// String implements the Stringer interface for AdaptiveSet.
+func (set *AdaptiveSet[T]) String() string {
+	return set.data.String()
+}
@@ -0,0 +1,228 @@
+package intset
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/knakk/specs"
+)
+
+func TestAdaptiveSetAdd(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewAdaptiveSet(0).Add(5, 1, 2, 5, 2)
+	specs.Expect(set.Representation(), ArrayRepresentation)
+	specs.Expect(set.All(), []int{1, 2, 5})
+	specs.Expect(set.Size(), 3)
+
+	set.Remove(2, 7)
+	specs.Expect(set.All(), []int{1, 5})
+	specs.Expect(set.Size(), 2)
+}
+
+func TestAdaptiveSetSparse(t *testing.T) {
+	specs := specs.New(t)
+
+	r := rand.New(rand.NewSource(1))
+	set := NewAdaptiveSet(0)
+	want := NewHashSet(0)
+	for set.Size() < 5000 {
+		v := r.Intn(1 << 40)
+		set.Add(v)
+		want.Add(v)
+	}
+	specs.Expect(set.Representation(), HashRepresentation)
+	specs.Expect(set.Equal(want), true)
+
+	// removing most integers converts it back to an array
+	for _, v := range want.All()[:4500] {
+		set.Remove(v)
+	}
+	specs.Expect(set.Size(), 500)
+	specs.Expect(set.Representation(), ArrayRepresentation)
+}
+
+func TestAdaptiveSetDense(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewAdaptiveSet(0)
+	for i := 0; i < 20000; i += 3 {
+		set.Add(i)
+	}
+	specs.Expect(set.Representation(), BitmapRepresentation)
+	specs.Expect(set.Size(), 6667)
+	specs.Expect(set.Contains(0, 3, 19998), true)
+	specs.Expect(set.Contains(1), false)
+
+	// a far away integer would make the bitmap too sparse
+	set.Add(1 << 40)
+	specs.Expect(set.Representation(), HashRepresentation)
+	specs.Expect(set.Size(), 6668)
+	specs.Expect(set.Contains(1<<40, 19998), true)
+}
+
+func TestAdaptiveSetRuns(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewAdaptiveSet(0).AddRange(1e6, 5e6).Remove(2e6, 3e6)
+	specs.Expect(set.Representation(), RunsRepresentation)
+	specs.Expect(set.Size(), int(4e6-1))
+	specs.Expect(set.Contains(1e6, 5e6), true)
+	specs.Expect(set.Contains(2e6), false)
+
+	// fragmented runs are converted to a bitmap
+	for i := 0; i < 20000; i += 2 {
+		set.Remove(1e6 + i)
+	}
+	specs.Expect(set.Representation(), RunsRepresentation)
+	set.RemoveRange(1e6+20000, 5e6)
+	specs.Expect(set.Size(), 10000)
+	specs.Expect(set.Representation(), BitmapRepresentation)
+
+	set.FlipRange(1e6, 1e6+19999)
+	specs.Expect(set.Size(), 10000)
+	specs.Expect(set.Contains(1e6, 1e6+2, 1e6+19998), true)
+	specs.Expect(set.Contains(1e6+1), false)
+	specs.Expect(set.Contains(1e6+19999), false)
+}
+
+func TestAdaptiveSetTune(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewAdaptiveSet(0).AddRange(1, 100)
+	specs.Expect(set.Representation(), RunsRepresentation)
+
+	set.Tune(AdaptiveThresholds{ArraySize: 1000, BitmapDensity: 0.5})
+	specs.Expect(set.Representation(), ArrayRepresentation)
+	set.Tune(AdaptiveThresholds{ArraySize: 10, BitmapDensity: 0.5})
+	specs.Expect(set.Representation(), BitmapRepresentation)
+	set.Tune(AdaptiveThresholds{ArraySize: 10, BitmapDensity: 2})
+	specs.Expect(set.Representation(), HashRepresentation)
+	specs.Expect(set.Size(), 100)
+	specs.Expect(set.Contains(1, 100), true)
+
+	// results keep the thresholds
+	specs.Expect(set.Clone().Representation(), HashRepresentation)
+	specs.Expect(set.Intersection(NewHashSet(0).AddRange(1, 50)).Representation(), HashRepresentation)
+
+	specs.Expect(ArrayRepresentation.String(), "array")
+	specs.Expect(RunsRepresentation.String(), "runs")
+}
+
+func TestAdaptiveSetAlgebra(t *testing.T) {
+	specs := specs.New(t)
+
+	a := NewAdaptiveSet(0).AddRange(0, 9999)
+	b := NewAdaptiveSet(0)
+	for i := 5000; i < 200000; i += 7 {
+		b.Add(i)
+	}
+	specs.Expect(a.Representation(), RunsRepresentation)
+	specs.Expect(b.Representation(), BitmapRepresentation)
+
+	ha := NewHashSet(0).Add(a.All()...)
+	hb := NewHashSet(0).Add(b.All()...)
+	specs.Expect(a.Union(b).Equal(ha.Union(hb)), true)
+	specs.Expect(a.Intersection(b).Equal(ha.Intersection(hb)), true)
+	specs.Expect(a.Difference(b).Equal(ha.Difference(hb)), true)
+	specs.Expect(a.SymetricDifference(b).Equal(ha.SymetricDifference(hb)), true)
+	specs.Expect(a.IntersectionCount(b), ha.IntersectionCount(hb))
+	specs.Expect(a.Intersects(b), true)
+	specs.Expect(a.Intersection(b).SubsetOf(b), true)
+	specs.Expect(b.SupersetOf(a.Intersection(b)), true)
+
+	// a far away set is not added to the bitmap
+	c := b.Clone().UnionWith(NewHashSet(0).Add(1<<40, 1<<41))
+	specs.Expect(c.Representation(), HashRepresentation)
+	specs.Expect(c.Size(), b.Size()+2)
+	specs.Expect(b.Representation(), BitmapRepresentation)
+}
+
+func TestAdaptiveSetInPlace(t *testing.T) {
+	specs := specs.New(t)
+
+	a := NewAdaptiveSet(0).Add(1, 2, 4, 10)
+	for _, b := range []IntSet{NewAdaptiveSet(0).Add(2, 3, 10, 15), NewHashSet(20).Add(2, 3, 10, 15)} {
+		specs.Expect(a.Clone().UnionWith(b).Equal(a.Union(b)), true)
+		specs.Expect(a.Clone().IntersectWith(b).Equal(a.Intersection(b)), true)
+		specs.Expect(a.Clone().DifferenceWith(b).Equal(a.Difference(b)), true)
+		specs.Expect(a.Clone().SymmetricDifferenceWith(b).Equal(a.SymetricDifference(b)), true)
+	}
+
+	c := a.Clone()
+	specs.Expect(c.UnionWith(c).Equal(a), true)
+	specs.Expect(c.IntersectWith(c).Equal(a), true)
+	specs.Expect(c.Clone().DifferenceWith(c).Size(), 0)
+	specs.Expect(c.SymmetricDifferenceWith(c).Size(), 0)
+}
+
+func TestAdaptiveSetInPlaceAllocations(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewAdaptiveSet(0)
+	for i := 0; i < 100000; i += 3 {
+		set.Add(i)
+	}
+	other := NewBitSet(0).AddRange(0, 200000)
+	odd := NewAdaptiveSet(0)
+	for i := 1; i < 100000; i += 6 {
+		odd.Add(i)
+	}
+	specs.Expect(set.Representation(), BitmapRepresentation)
+	specs.Expect(odd.Representation(), BitmapRepresentation)
+
+	allocs := testing.AllocsPerRun(10, func() {
+		set.IntersectWith(other)
+		set.DifferenceWith(odd)
+	})
+	specs.Expect(allocs, 0.0)
+	specs.Expect(set.Size(), 33334)
+	specs.Expect(set.Representation(), BitmapRepresentation)
+}
+
+func TestAdaptiveSetUnmarshal(t *testing.T) {
+	specs := specs.New(t)
+
+	data, _ := NewIntervalSet(0).AddRange(1, 1000).MarshalBinary()
+	var set AdaptiveSet[int]
+	specs.Expect(set.UnmarshalBinary(data), nil)
+	specs.Expect(set.Size(), 1000)
+	specs.Expect(set.Representation(), RunsRepresentation)
+
+	text, _ := set.MarshalText()
+	specs.Expect(string(text), "1-1000")
+}
+
+// Benchmarks
+
+func BenchmarkAdaptiveSetAdd(b *testing.B) {
+	set := NewAdaptiveSet(0)
+	for i := 0; i < b.N; i++ {
+		set.Add(rand.Intn(1000000))
+	}
+}
+
+func BenchmarkAdaptiveSetContains(b *testing.B) {
+	set := NewAdaptiveSet(0)
+	for i := 0; i < 500; i++ {
+		set.Add(rand.Intn(1000))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		set.Contains(rand.Intn(1000))
+	}
+}
+
+func BenchmarkAdaptiveSetBigUnion(b *testing.B) {
+	setA := NewAdaptiveSet(0)
+	setB := NewAdaptiveSet(0)
+	for i := 0; i < 5000; i++ {
+		setA.Add(rand.Intn(10000))
+		setB.Add(rand.Intn(10000))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = setA.Union(setB)
+	}
+}
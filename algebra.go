@@ -0,0 +1,357 @@
+package intset
+
+import (
+	"iter"
+	"slices"
+)
+
+// UnionAll returns a new set which is the union of all the sets. The result
+// has the type of the largest set. It returns nil if no sets are given.
+//
+// Sets of the same type are merged natively, in place. Otherwise the other
+// sets are added to a clone of the largest set with its in-place union, which
+// is native for the pairs of types which support it.
+func UnionAll[T Integer](sets ...Set[T]) Set[T] {
+	if len(sets) == 0 {
+		return nil
+	}
+	sets = bySize(sets, true)
+	if result, ok := nativeAll(sets, true); ok {
+		return result
+	}
+
+	result := sets[0].CloneSet()
+	if g, ok := result.(grower[T]); ok {
+		for _, s := range sets[1:] {
+			g.growFor(s)
+		}
+	}
+	for _, s := range sets[1:] {
+		if !applyWith(result, opUnionWith, s) {
+			for i := range s.Values() {
+				result.Insert(i)
+			}
+		}
+	}
+	return result
+}
+
+// IntersectAll returns a new set with the integers common to all the sets.
+// The result has the type of the smallest set. It returns nil if no sets are
+// given.
+//
+// The smallest set drives the work: sets of the same type are intersected
+// natively, in place, from the smallest to the largest, stopping as soon as
+// the intermediate result is empty. Otherwise each integer of the smallest set
+// is looked up in the other sets, from the smallest to the largest, until one
+// of them doesn't contain it.
+func IntersectAll[T Integer](sets ...Set[T]) Set[T] {
+	if len(sets) == 0 {
+		return nil
+	}
+	sets = bySize(sets, false)
+	if sets[0].Size() == 0 {
+		return sets[0].CloneSet()
+	}
+	if result, ok := nativeAll(sets, false); ok {
+		return result
+	}
+
+	result := sets[0].CloneSet()
+	for i := range sets[0].Values() {
+		for _, s := range sets[1:] {
+			if !s.Contains(i) {
+				result.Delete(i)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// intersectionCount returns the number of integers common to a and b, by
+// looking up the integers of the smaller set in the larger one.
+func intersectionCount[T Integer](a, b Set[T]) int {
+	if a.Size() > b.Size() {
+		a, b = b, a
+	}
+	var n int
+	each(a, func(i T) bool {
+		if has(b, i) {
+			n++
+		}
+		return true
+	})
+	return n
+}
+
+// intersects returns true if a and b have at least one integer in common.
+func intersects[T Integer](a, b Set[T]) bool {
+	if a.Size() > b.Size() {
+		a, b = b, a
+	}
+	var found bool
+	each(a, func(i T) bool {
+		found = has(b, i)
+		return !found
+	})
+	return found
+}
+
+// each calls fn with each integer in set, until fn returns false. The
+// iterators of the set types of this package are ranged over directly rather
+// than through the Set interface, so that they are inlined and don't allocate.
+// The integers of other set types are copied with All.
+func each[T Integer](set Set[T], fn func(T) bool) {
+	switch s := set.(type) {
+	case *AdaptiveSet[T]:
+		each(s.data, fn)
+		return
+	case *ArraySet[T]:
+		for i := range s.Values() {
+			if !fn(i) {
+				return
+			}
+		}
+		return
+	case *BitSet[T]:
+		for i := range s.Values() {
+			if !fn(i) {
+				return
+			}
+		}
+		return
+	case *BriggsSet[T]:
+		for i := range s.Values() {
+			if !fn(i) {
+				return
+			}
+		}
+		return
+	case *HashSet[T]:
+		for i := range s.Values() {
+			if !fn(i) {
+				return
+			}
+		}
+		return
+	case *IntervalSet[T]:
+		for i := range s.Values() {
+			if !fn(i) {
+				return
+			}
+		}
+		return
+	case *SliceSet[T]:
+		for i := range s.Values() {
+			if !fn(i) {
+				return
+			}
+		}
+		return
+	}
+	// a RoaringSet is only a Set[T] when T is int
+	if r, ok := any(set).(*RoaringSet); ok {
+		r.each(func(i int) bool { return fn(T(i)) })
+		return
+	}
+	for _, i := range set.All() {
+		if !fn(i) {
+			return
+		}
+	}
+}
+
+// has returns true if v is in set. Like each, it calls the Contains method of
+// the set types of this package directly, so that its argument doesn't escape.
+func has[T Integer](set Set[T], v T) bool {
+	switch s := set.(type) {
+	case *AdaptiveSet[T]:
+		return has(s.data, v)
+	case *ArraySet[T]:
+		return s.Contains(v)
+	case *BitSet[T]:
+		return s.Contains(v)
+	case *BriggsSet[T]:
+		return s.Contains(v)
+	case *HashSet[T]:
+		return s.Contains(v)
+	case *IntervalSet[T]:
+		return s.Contains(v)
+	case *SliceSet[T]:
+		return s.Contains(v)
+	}
+	// a RoaringSet is only a Set[T] when T is int
+	if r, ok := any(set).(*RoaringSet); ok {
+		return r.Contains(int(v))
+	}
+	return set.Contains(v)
+}
+
+// bySize returns a copy of sets sorted by size, in ascending order or in
+// descending order if desc is true. The size of each set is computed once.
+func bySize[T Integer](sets []Set[T], desc bool) []Set[T] {
+	type sized struct {
+		set  Set[T]
+		size int
+	}
+	s := make([]sized, len(sets))
+	for k, set := range sets {
+		s[k] = sized{set, set.Size()}
+	}
+	slices.SortStableFunc(s, func(a, b sized) int {
+		if desc {
+			return b.size - a.size
+		}
+		return a.size - b.size
+	})
+	result := make([]Set[T], len(s))
+	for k := range s {
+		result[k] = s[k].set
+	}
+	return result
+}
+
+// inPlace is implemented by the set types with in-place set algebra, where S
+// is the set type itself.
+type inPlace[T Integer, S any] interface {
+	Set[T]
+	Clone() S
+	UnionWith(other Set[T]) S
+	IntersectWith(other Set[T]) S
+	DifferenceWith(other Set[T]) S
+	SymmetricDifferenceWith(other Set[T]) S
+}
+
+// In-place operations of withOf.
+const (
+	opUnionWith = iota
+	opIntersectWith
+	opDifferenceWith
+	opSymmetricDifferenceWith
+)
+
+func withOf[S inPlace[T, S], T Integer](s S, op int, other Set[T]) {
+	switch op {
+	case opUnionWith:
+		s.UnionWith(other)
+	case opIntersectWith:
+		s.IntersectWith(other)
+	case opDifferenceWith:
+		s.DifferenceWith(other)
+	case opSymmetricDifferenceWith:
+		s.SymmetricDifferenceWith(other)
+	}
+}
+
+// applyWith applies the in-place operation op with other to set, using the
+// in-place method of the set type. It returns false if the set type is not
+// one of this package.
+func applyWith[T Integer](set Set[T], op int, other Set[T]) bool {
+	switch s := set.(type) {
+	case *AdaptiveSet[T]:
+		withOf(s, op, other)
+	case *ArraySet[T]:
+		withOf(s, op, other)
+	case *BitSet[T]:
+		withOf(s, op, other)
+	case *BriggsSet[T]:
+		withOf(s, op, other)
+	case *HashSet[T]:
+		withOf(s, op, other)
+	case *IntervalSet[T]:
+		withOf(s, op, other)
+	case *SliceSet[T]:
+		withOf(s, op, other)
+	case *SyncSet[T]:
+		withOf(s, op, other)
+	default:
+		// a RoaringSet is only a Set[T] when T is int
+		r, ok := any(set).(*RoaringSet)
+		if !ok {
+			return false
+		}
+		withOf(r, op, any(other).(IntSet))
+	}
+	return true
+}
+
+// nativeAll returns the union, or the intersection, of the sets if they are
+// all of the same type. The last return value is false if they are not.
+func nativeAll[T Integer](sets []Set[T], union bool) (Set[T], bool) {
+	switch sets[0].(type) {
+	case *AdaptiveSet[T]:
+		return allOf[*AdaptiveSet[T]](sets, union)
+	case *ArraySet[T]:
+		return allOf[*ArraySet[T]](sets, union)
+	case *BitSet[T]:
+		return allOf[*BitSet[T]](sets, union)
+	case *BriggsSet[T]:
+		return allOf[*BriggsSet[T]](sets, union)
+	case *HashSet[T]:
+		return allOf[*HashSet[T]](sets, union)
+	case *IntervalSet[T]:
+		return allOf[*IntervalSet[T]](sets, union)
+	case *SliceSet[T]:
+		return allOf[*SliceSet[T]](sets, union)
+	}
+	// a RoaringSet is only a Set[T] when T is int
+	switch any(*new(T)).(type) {
+	case int:
+		if _, ok := any(sets[0]).(*RoaringSet); !ok {
+			break
+		}
+		ints := make([]IntSet, len(sets))
+		for k, s := range sets {
+			ints[k] = any(s).(IntSet)
+		}
+		if result, ok := allOf[*RoaringSet](ints, union); ok {
+			return any(result).(Set[T]), true
+		}
+	}
+	return nil, false
+}
+
+func allOf[S inPlace[T, S], T Integer](sets []Set[T], union bool) (Set[T], bool) {
+	for _, s := range sets {
+		if _, ok := s.(S); !ok {
+			return nil, false
+		}
+	}
+	result := sets[0].(S).Clone()
+	for _, s := range sets[1:] {
+		if union {
+			result.UnionWith(s)
+			continue
+		}
+		if isEmpty[T](result) {
+			break
+		}
+		result.IntersectWith(s)
+	}
+	return result, true
+}
+
+// isEmpty returns true if set is empty. The size of a BitSet or a RoaringSet
+// is counted over all its storage, so they are checked with Max, which takes
+// constant time for them.
+func isEmpty[T Integer](set Set[T]) bool {
+	switch any(set).(type) {
+	case *BitSet[T], *RoaringSet:
+		_, ok := set.Max()
+		return !ok
+	}
+	return set.Size() == 0
+}
+
+// grower is implemented by the set types with a fixed range, which can be
+// enlarged to hold the integers of another set.
+type grower[T Integer] interface {
+	growFor(other Set[T])
+}
+
+// ordered is implemented by the set types which iterate over their integers
+// in ascending order.
+type ordered[T Integer] interface {
+	Backward() iter.Seq[T]
+}
@@ -0,0 +1,101 @@
+package intset
+
+import (
+	"testing"
+
+	"github.com/knakk/specs"
+)
+
+func TestUnionAll(t *testing.T) {
+	specs := specs.New(t)
+
+	want := NewHashSet(0).Add(1, 2, 3, 4, 5, 40, 64, 100)
+	for _, fa := range newSets {
+		for _, fb := range newSets {
+			result := UnionAll(
+				newIntSet(fa, 10, 1, 2, 3),
+				newIntSet(fb, 100, 2, 64, 100),
+				newIntSet(fa, 50, 4, 5, 40),
+				newIntSet(fb, 10),
+			)
+			specs.Expect(result.Equal(want), true)
+		}
+	}
+
+	specs.Expect(UnionAll[int]() == nil, true)
+	set := NewBitSet(10).Add(1, 2)
+	result := UnionAll[int](set)
+	specs.Expect(result.Equal(set), true)
+	result.Insert(3)
+	specs.Expect(set.Contains(3), false)
+}
+
+func TestIntersectAll(t *testing.T) {
+	specs := specs.New(t)
+
+	want := NewHashSet(0).Add(2, 64)
+	for _, fa := range newSets {
+		for _, fb := range newSets {
+			result := IntersectAll(
+				newIntSet(fa, 100, 1, 2, 3, 64, 99),
+				newIntSet(fb, 100, 2, 64, 100),
+				newIntSet(fa, 100, 2, 5, 40, 64, 70, 100),
+			)
+			specs.Expect(result.Equal(want), true)
+
+			result = IntersectAll(
+				newIntSet(fa, 100, 1, 2, 3),
+				newIntSet(fb, 100, 4, 5),
+				newIntSet(fa, 100, 1, 2, 3, 4, 5),
+			)
+			specs.Expect(result.Size(), 0)
+
+			result = IntersectAll(newIntSet(fa, 100, 1, 2, 3), newIntSet(fb, 100))
+			specs.Expect(result.Size(), 0)
+		}
+	}
+
+	specs.Expect(IntersectAll[int]() == nil, true)
+}
+
+func TestUnionAllMixed(t *testing.T) {
+	specs := specs.New(t)
+
+	sets := []IntSet{
+		NewBitSet(0).Add(1, 5, 64, 200),
+		NewSliceSet(100).Add(0, 5, 6, 100),
+		NewRoaringSet(0).Add(6, 1<<20),
+		Synchronized[int](NewIntervalSet(0).AddRange(300, 310)),
+		NewBitSet(0),
+	}
+	result := UnionAll(sets...)
+	specs.Expect(result.Size(), 19)
+	for _, s := range sets {
+		specs.Expect(s.SubsetOf(result), true)
+	}
+	specs.Expect(UnionAll(sets[1], NewHashSet(0).Add(-5, 1000)).Size(), 6)
+}
+
+// Benchmarks
+
+func benchmarkIntersectAll(b *testing.B, f func(int) IntSet) {
+	sets := make([]IntSet, 50)
+	for k := range sets {
+		sets[k] = f(100000)
+		for i := k % 7; i <= 100000; i += 3 + k%5 {
+			sets[k].Insert(i)
+		}
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		IntersectAll(sets...)
+	}
+}
+
+func BenchmarkIntersectAllBitSet(b *testing.B) {
+	benchmarkIntersectAll(b, func(max int) IntSet { return NewBitSet(max) })
+}
+
+func BenchmarkIntersectAllHashSet(b *testing.B) {
+	benchmarkIntersectAll(b, func(max int) IntSet { return NewHashSet(max) })
+}
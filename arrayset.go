@@ -0,0 +1,638 @@
+package intset
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+)
+
+// gallopRatio is the ratio between the sizes of two sorted slices from which
+// the integers of the smaller one are looked up in the larger one by galloping,
+// instead of merging the slices linearly.
+const gallopRatio = 16
+
+// ArraySet is an integer set backed by a sorted slice. It is compact for small
+// and medium sized sparse sets, and iterates over its integers in ascending
+// order.
+type ArraySet[T Integer] struct {
+	data []T
+}
+
+// NewArraySet is the constructor for an ArraySet of ints.
+func NewArraySet(max int) *ArraySet[int] {
+	return NewArraySetOf[int](max)
+}
+
+// NewArraySetOf is the constructor for an ArraySet of any integer type.
+func NewArraySetOf[T Integer](max int) *ArraySet[T] {
+	return new(ArraySet[T]).init(max)
+}
+
+// NewArraySetFrom returns a new ArraySet holding the given integers, which
+// may be unsorted and contain duplicates. The slice is not modified.
+func NewArraySetFrom[T Integer](ints []T) *ArraySet[T] {
+	return &ArraySet[T]{data: sortedSet(slices.Clone(ints))}
+}
+
+func (set *ArraySet[T]) init(max int) *ArraySet[T] {
+	set.data = nil
+	return set
+}
+
+// sortedSet sorts the integers and removes duplicates, in place.
+func sortedSet[T Integer](ints []T) []T {
+	slices.Sort(ints)
+	return slices.Clip(slices.Compact(ints))
+}
+
+// Clear the set.
+func (set *ArraySet[T]) Clear() *ArraySet[T] {
+	set.data = set.data[:0]
+	return set
+}
+
+// Size returns the number of integers in the set.
+func (set *ArraySet[T]) Size() int {
+	return len(set.data)
+}
+
+// Add one or more integers to the set.
+func (set *ArraySet[T]) Add(ints ...T) *ArraySet[T] {
+	if len(ints) > 1 {
+		set.data = unionSorted(set.data, sortedSet(slices.Clone(ints)))
+		return set
+	}
+	for _, v := range ints {
+		if n := len(set.data); n == 0 || set.data[n-1] < v {
+			set.data = append(set.data, v)
+		} else if k, found := slices.BinarySearch(set.data, v); !found {
+			set.data = slices.Insert(set.data, k, v)
+		}
+	}
+	return set
+}
+
+// Remove one or more integers from the set.
+func (set *ArraySet[T]) Remove(ints ...T) *ArraySet[T] {
+	for _, v := range ints {
+		if k, found := slices.BinarySearch(set.data, v); found {
+			set.data = slices.Delete(set.data, k, k+1)
+		}
+	}
+	return set
+}
+
+// AddRange adds the integers from lo to hi, inclusive, to the set. It panics
+// if the range holds more than 2^40 integers.
+func (set *ArraySet[T]) AddRange(lo, hi T) *ArraySet[T] {
+	if hi < lo {
+		return set
+	}
+	n := set.checkSpan(lo, hi)
+	i, j := set.span(lo, hi)
+	r := make([]T, 0, n)
+	for v := lo; ; v++ {
+		r = append(r, v)
+		if v == hi {
+			break
+		}
+	}
+	set.data = slices.Replace(set.data, i, j, r...)
+	return set
+}
+
+// RemoveRange removes the integers from lo to hi, inclusive, from the set.
+func (set *ArraySet[T]) RemoveRange(lo, hi T) *ArraySet[T] {
+	if hi < lo {
+		return set
+	}
+	i, j := set.span(lo, hi)
+	set.data = slices.Delete(set.data, i, j)
+	return set
+}
+
+// FlipRange adds the integers from lo to hi, inclusive, which are not in the
+// set, and removes those which are. It panics if the range holds more than
+// 2^40 integers.
+func (set *ArraySet[T]) FlipRange(lo, hi T) *ArraySet[T] {
+	if hi < lo {
+		return set
+	}
+	set.checkSpan(lo, hi)
+	i, j := set.span(lo, hi)
+	old := set.data[i:j]
+	var r []T
+	for v := lo; ; v++ {
+		if len(old) > 0 && old[0] == v {
+			old = old[1:]
+		} else {
+			r = append(r, v)
+		}
+		if v == hi {
+			break
+		}
+	}
+	set.data = slices.Replace(set.data, i, j, r...)
+	return set
+}
+
+// checkSpan returns the number of integers from lo to hi, inclusive. It panics
+// if there are more than maxDense, as the set cannot hold them.
+func (set *ArraySet[T]) checkSpan(lo, hi T) int {
+	n := uint64(hi) - uint64(lo)
+	if n >= maxDense {
+		panic(fmt.Sprintf("intset: ArraySet cannot hold the integers from %v to %v", lo, hi))
+	}
+	return int(n) + 1
+}
+
+// span returns the positions of the first integer in the set which is not
+// less than lo, and of the first one which is greater than hi.
+func (set *ArraySet[T]) span(lo, hi T) (int, int) {
+	i, _ := slices.BinarySearch(set.data, lo)
+	j, found := slices.BinarySearch(set.data[i:], hi)
+	if found {
+		j++
+	}
+	return i, i + j
+}
+
+// All returns a slice of all the integers in the set, in ascending order.
+func (set *ArraySet[T]) All() []T {
+	return append([]T(nil), set.data...)
+}
+
+// Values returns an iterator over the integers in the set in ascending order.
+func (set *ArraySet[T]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, v := range set.data {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Backward returns an iterator over the integers in the set in descending
+// order.
+func (set *ArraySet[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for k := len(set.data) - 1; k >= 0; k-- {
+			if !yield(set.data[k]) {
+				return
+			}
+		}
+	}
+}
+
+// Min returns the smallest integer in the set. The second return value is
+// false if the set is empty.
+func (set *ArraySet[T]) Min() (T, bool) {
+	if len(set.data) == 0 {
+		return 0, false
+	}
+	return set.data[0], true
+}
+
+// Max returns the largest integer in the set. The second return value is false
+// if the set is empty.
+func (set *ArraySet[T]) Max() (T, bool) {
+	if len(set.data) == 0 {
+		return 0, false
+	}
+	return set.data[len(set.data)-1], true
+}
+
+// NextAfter returns the smallest integer in the set which is greater than v.
+// The second return value is false if there is no such integer.
+func (set *ArraySet[T]) NextAfter(v T) (T, bool) {
+	if k := set.Rank(v); k < len(set.data) {
+		return set.data[k], true
+	}
+	return 0, false
+}
+
+// PrevBefore returns the largest integer in the set which is less than v. The
+// second return value is false if there is no such integer.
+func (set *ArraySet[T]) PrevBefore(v T) (T, bool) {
+	if k, _ := slices.BinarySearch(set.data, v); k > 0 {
+		return set.data[k-1], true
+	}
+	return 0, false
+}
+
+// Contains returns true if all ints are in the set, otherwise false.
+func (set *ArraySet[T]) Contains(ints ...T) bool {
+	for _, v := range ints {
+		if _, found := slices.BinarySearch(set.data, v); !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Rank returns the number of integers in the set which are less than or equal
+// to v.
+func (set *ArraySet[T]) Rank(v T) int {
+	k, found := slices.BinarySearch(set.data, v)
+	if found {
+		k++
+	}
+	return k
+}
+
+// Select returns the k-th smallest integer in the set, counting from 0. The
+// second return value is false if k is not less than the size of the set.
+func (set *ArraySet[T]) Select(k int) (T, bool) {
+	if k < 0 || k >= len(set.data) {
+		return 0, false
+	}
+	return set.data[k], true
+}
+
+// Equal checks if two sets both contains all the same items.
+func (set *ArraySet[T]) Equal(other Set[T]) bool {
+	if o, ok := other.(*ArraySet[T]); ok {
+		return slices.Equal(set.data, o.data)
+	}
+	return set.Size() == other.Size() && set.SubsetOf(other)
+}
+
+// SubsetOf checks if all items in set are also present in other set.
+func (set *ArraySet[T]) SubsetOf(other Set[T]) bool {
+	if o, ok := other.(*ArraySet[T]); ok {
+		return len(set.data) <= len(o.data) && set.IntersectionCount(o) == len(set.data)
+	}
+	for _, v := range set.data {
+		if !other.Contains(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// SupersetOf checks if a set is a superset of another set.
+func (set *ArraySet[T]) SupersetOf(other Set[T]) bool {
+	return other.SubsetOf(set)
+}
+
+// Union returns a new set which is the union of two sets.
+func (set *ArraySet[T]) Union(other Set[T]) *ArraySet[T] {
+	return &ArraySet[T]{data: unionSorted(set.data, sortedOf(other))}
+}
+
+// Intersection returns a new set with integers common to both sets.
+func (set *ArraySet[T]) Intersection(other Set[T]) *ArraySet[T] {
+	result := &ArraySet[T]{}
+	if o, ok := other.(*ArraySet[T]); ok {
+		commonSorted(set.data, o.data, func(v T) bool {
+			result.data = append(result.data, v)
+			return true
+		})
+		return result
+	}
+	for _, v := range set.data {
+		if other.Contains(v) {
+			result.data = append(result.data, v)
+		}
+	}
+	return result
+}
+
+// Difference returns a new set with the integers in set which are not in other.
+func (set *ArraySet[T]) Difference(other Set[T]) *ArraySet[T] {
+	if o, ok := other.(*ArraySet[T]); ok {
+		return &ArraySet[T]{data: differenceSorted(set.data, o.data)}
+	}
+	result := &ArraySet[T]{}
+	for _, v := range set.data {
+		if !other.Contains(v) {
+			result.data = append(result.data, v)
+		}
+	}
+	return result
+}
+
+// SymetricDifference returns a new set with the integers in current and other,
+// but not in both.
+func (set *ArraySet[T]) SymetricDifference(other Set[T]) *ArraySet[T] {
+	return &ArraySet[T]{data: xorSorted(set.data, sortedOf(other))}
+}
+
+// UnionWith adds the integers in other to the set. It is the in-place variant
+// of Union.
+func (set *ArraySet[T]) UnionWith(other Set[T]) *ArraySet[T] {
+	set.data = unionSorted(set.data, sortedOf(other))
+	return set
+}
+
+// IntersectWith removes the integers which are not in other from the set. It
+// is the in-place variant of Intersection.
+func (set *ArraySet[T]) IntersectWith(other Set[T]) *ArraySet[T] {
+	if o, ok := other.(*ArraySet[T]); ok {
+		var n int
+		commonSorted(set.data, o.data, func(v T) bool {
+			set.data[n] = v
+			n++
+			return true
+		})
+		set.data = set.data[:n]
+		return set
+	}
+	set.data = slices.DeleteFunc(set.data, func(v T) bool { return !other.Contains(v) })
+	return set
+}
+
+// DifferenceWith removes the integers in other from the set. It is the
+// in-place variant of Difference.
+func (set *ArraySet[T]) DifferenceWith(other Set[T]) *ArraySet[T] {
+	if o, ok := other.(*ArraySet[T]); ok {
+		set.data = differenceSorted(set.data, o.data)
+		return set
+	}
+	set.data = slices.DeleteFunc(set.data, func(v T) bool { return other.Contains(v) })
+	return set
+}
+
+// SymmetricDifferenceWith adds the integers in other which are not in the set,
+// and removes those which are. It is the in-place variant of
+// SymetricDifference.
+func (set *ArraySet[T]) SymmetricDifferenceWith(other Set[T]) *ArraySet[T] {
+	set.data = xorSorted(set.data, sortedOf(other))
+	return set
+}
+
+// IntersectionCount returns the number of integers common to both sets,
+// without building the intersection.
+func (set *ArraySet[T]) IntersectionCount(other Set[T]) int {
+	o, ok := other.(*ArraySet[T])
+	if !ok {
+		return intersectionCount[T](set, other)
+	}
+	var n int
+	commonSorted(set.data, o.data, func(T) bool {
+		n++
+		return true
+	})
+	return n
+}
+
+// UnionCount returns the number of integers in the union of two sets, without
+// building the union.
+func (set *ArraySet[T]) UnionCount(other Set[T]) int {
+	return set.Size() + other.Size() - set.IntersectionCount(other)
+}
+
+// DifferenceCount returns the number of integers in set which are not in
+// other, without building the difference.
+func (set *ArraySet[T]) DifferenceCount(other Set[T]) int {
+	return set.Size() - set.IntersectionCount(other)
+}
+
+// SymmetricDifferenceCount returns the number of integers in current and
+// other, but not in both, without building the symmetric difference.
+func (set *ArraySet[T]) SymmetricDifferenceCount(other Set[T]) int {
+	return set.Size() + other.Size() - 2*set.IntersectionCount(other)
+}
+
+// Intersects returns true if the sets have at least one integer in common.
+func (set *ArraySet[T]) Intersects(other Set[T]) bool {
+	o, ok := other.(*ArraySet[T])
+	if !ok {
+		return intersects[T](set, other)
+	}
+	var found bool
+	commonSorted(set.data, o.data, func(T) bool {
+		found = true
+		return false
+	})
+	return found
+}
+
+// Clone returns a new set which is a clone of current set.
+func (set *ArraySet[T]) Clone() *ArraySet[T] {
+	return &ArraySet[T]{data: slices.Clone(set.data)}
+}
+
+// sortedOf returns the integers in the set in ascending order. The slice must
+// not be modified, as it may be the backing slice of an ArraySet.
+func sortedOf[T Integer](set Set[T]) []T {
+	if o, ok := set.(*ArraySet[T]); ok {
+		return o.data
+	}
+	ints := set.All()
+	if _, ok := set.(ordered[T]); !ok {
+		slices.Sort(ints)
+	}
+	return ints
+}
+
+// unionSorted returns a new slice with the union of the sorted slices a and b,
+// by merging them linearly.
+func unionSorted[T Integer](a, b []T) []T {
+	result := make([]T, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] < b[j]:
+			result = append(result, a[i])
+			i++
+		case a[i] > b[j]:
+			result = append(result, b[j])
+			j++
+		default:
+			result = append(result, a[i])
+			i++
+			j++
+		}
+	}
+	result = append(result, a[i:]...)
+	return append(result, b[j:]...)
+}
+
+// differenceSorted returns a new slice with the integers of the sorted slice a
+// which are not in the sorted slice b, by merging them linearly.
+func differenceSorted[T Integer](a, b []T) []T {
+	result := make([]T, 0, len(a))
+	j := 0
+	for _, v := range a {
+		for j < len(b) && b[j] < v {
+			j++
+		}
+		if j == len(b) || b[j] != v {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// xorSorted returns a new slice with the integers in one of the sorted slices
+// a and b, but not in both, by merging them linearly.
+func xorSorted[T Integer](a, b []T) []T {
+	result := make([]T, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] < b[j]:
+			result = append(result, a[i])
+			i++
+		case a[i] > b[j]:
+			result = append(result, b[j])
+			j++
+		default:
+			i++
+			j++
+		}
+	}
+	result = append(result, a[i:]...)
+	return append(result, b[j:]...)
+}
+
+// commonSorted calls fn for each integer common to the sorted slices a and b,
+// in ascending order, until fn returns false. When one slice is much smaller
+// than the other, its integers are looked up in the larger one by galloping,
+// otherwise the slices are merged linearly.
+func commonSorted[T Integer](a, b []T, fn func(T) bool) {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	if len(a)*gallopRatio < len(b) {
+		var k int
+		for _, v := range a {
+			if k = gallop(b, v, k); k == len(b) {
+				return
+			}
+			if b[k] == v {
+				if !fn(v) {
+					return
+				}
+				k++
+			}
+		}
+		return
+	}
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			if !fn(a[i]) {
+				return
+			}
+			i++
+			j++
+		}
+	}
+}
+
+// gallop returns the position of the first integer in the sorted slice s, from
+// position k, which is not less than v. It steps forward with doubling steps
+// until it passes v, then binary searches the last step.
+func gallop[T Integer](s []T, v T, k int) int {
+	if k >= len(s) || s[k] >= v {
+		return k
+	}
+	step := 1
+	for k+step < len(s) && s[k+step] < v {
+		k += step
+		step *= 2
+	}
+	j, _ := slices.BinarySearch(s[k+1:min(k+step, len(s))], v)
+	return k + 1 + j
+}
+
+// Insert adds one or more integers to the set. It is the Set adapter for Add.
+func (set *ArraySet[T]) Insert(ints ...T) {
+	set.Add(ints...)
+}
+
+// Delete removes one or more integers from the set. It is the Set adapter
+// for Remove.
+func (set *ArraySet[T]) Delete(ints ...T) {
+	set.Remove(ints...)
+}
+
+// CloneSet is the Set adapter for Clone.
+func (set *ArraySet[T]) CloneSet() Set[T] {
+	return set.Clone()
+}
+
+// UnionSet is the Set adapter for Union.
+func (set *ArraySet[T]) UnionSet(other Set[T]) Set[T] {
+	return set.Union(other)
+}
+
+// IntersectionSet is the Set adapter for Intersection.
+func (set *ArraySet[T]) IntersectionSet(other Set[T]) Set[T] {
+	return set.Intersection(other)
+}
+
+// DifferenceSet is the Set adapter for Difference.
+func (set *ArraySet[T]) DifferenceSet(other Set[T]) Set[T] {
+	return set.Difference(other)
+}
+
+// SymetricDifferenceSet is the Set adapter for SymetricDifference.
+func (set *ArraySet[T]) SymetricDifferenceSet(other Set[T]) Set[T] {
+	return set.SymetricDifference(other)
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (set *ArraySet[T]) MarshalBinary() ([]byte, error) {
+	return marshalBinary(tagArraySet, set.All()), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface. The
+// data may have been marshalled from any set type.
+func (set *ArraySet[T]) UnmarshalBinary(data []byte) error {
+	ints, err := unmarshalBinary[T](data)
+	if err != nil {
+		return err
+	}
+	return set.load(ints)
+}
+
+// MarshalJSON implements the json.Marshaler interface. The set is marshalled
+// as an array of integers in ascending order.
+func (set *ArraySet[T]) MarshalJSON() ([]byte, error) {
+	return marshalJSON(set.All()), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It accepts an array
+// of integers or a string in the form produced by MarshalText.
+func (set *ArraySet[T]) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, set.load, expanded(set.load))
+}
+
+// MarshalText implements the encoding.TextMarshaler interface. The set is
+// marshalled as a comma separated list of integers and ranges of integers in
+// ascending order, e.g. "1-5,9".
+func (set *ArraySet[T]) MarshalText() ([]byte, error) {
+	return marshalText(set.All()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface. It accepts
+// the form produced by MarshalText as well as the one produced by String.
+func (set *ArraySet[T]) UnmarshalText(text []byte) error {
+	return unmarshalText(text, expanded(set.load))
+}
+
+// load replaces the contents of the set with the given integers.
+func (set *ArraySet[T]) load(ints []T) error {
+	set.data = sortedSet(ints)
+	return nil
+}
+
+// String implements the Stringer interface for ArraySet.
+func (set *ArraySet[T]) String() string {
+	items := make([]string, 0, len(set.data))
+
+	for _, v := range set.data {
+		items = append(items, fmt.Sprintf("%v", v))
+	}
+	return fmt.Sprintf("Set{%s}", strings.Join(items, ", "))
+}
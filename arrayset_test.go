@@ -0,0 +1,294 @@
+package intset
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/knakk/specs"
+)
+
+func TestArraySetAdd(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewArraySet(0).Add(5, 1, 2, 5, 2)
+	specs.Expect(set.All(), []int{1, 2, 5})
+
+	set.Add(9).Add(3).Add(0).Add(3)
+	specs.Expect(set.All(), []int{0, 1, 2, 3, 5, 9})
+	specs.Expect(set.Size(), 6)
+}
+
+func TestArraySetRemove(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewArraySet(0).Add(3, 1, 7)
+	specs.Expect(set.Contains(3, 1), true)
+	set.Remove(1, 3, 4)
+	specs.Expect(set.Contains(1), false)
+	specs.Expect(set.Contains(3), false)
+	specs.Expect(set.All(), []int{7})
+}
+
+func TestArraySetFrom(t *testing.T) {
+	specs := specs.New(t)
+
+	ints := []int{9, -3, 1, 9, 4, 1}
+	set := NewArraySetFrom(ints)
+	specs.Expect(set.All(), []int{-3, 1, 4, 9})
+	specs.Expect(ints, []int{9, -3, 1, 9, 4, 1})
+
+	set.Add(2)
+	specs.Expect(set.All(), []int{-3, 1, 2, 4, 9})
+	specs.Expect(NewArraySetFrom([]uint8{}).Size(), 0)
+}
+
+func TestArraySetRanges(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewArraySet(0).Add(1, 200).AddRange(10, 140)
+	specs.Expect(set.Size(), 133)
+	specs.Expect(set.Contains(1, 10, 63, 64, 140, 200), true)
+	specs.Expect(set.Contains(9), false)
+	specs.Expect(set.Contains(141), false)
+
+	set.RemoveRange(20, 129)
+	specs.Expect(set.Size(), 23)
+	specs.Expect(set.Contains(19, 130), true)
+	specs.Expect(set.Contains(20), false)
+	specs.Expect(set.Contains(129), false)
+
+	set.FlipRange(15, 25)
+	specs.Expect(set.Size(), 24)
+	specs.Expect(set.Contains(14, 20, 25), true)
+	specs.Expect(set.Contains(15), false)
+	specs.Expect(set.Contains(26), false)
+
+	set.RemoveRange(-5, 1000)
+	specs.Expect(set.Size(), 0)
+	set.AddRange(5, 4)
+	specs.Expect(set.Size(), 0)
+
+	bytes := NewArraySetOf[uint8](0).AddRange(250, 255)
+	specs.Expect(bytes.All(), []uint8{250, 251, 252, 253, 254, 255})
+}
+
+func TestArraySetRangeTooLarge(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewArraySet(0).Add(5)
+	for _, f := range []func(lo, hi int) *ArraySet[int]{set.AddRange, set.FlipRange} {
+		func() {
+			defer func() {
+				specs.Expect(fmt.Sprint(recover()), "intset: ArraySet cannot hold the integers from 0 to 9223372036854775807")
+			}()
+			f(0, math.MaxInt)
+		}()
+	}
+	specs.Expect(set.All(), []int{5})
+}
+
+func TestArraySetNavigation(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewArraySet(0).Add(99, 1, 5)
+
+	var all []int
+	for i := range set.Backward() {
+		all = append(all, i)
+	}
+	specs.Expect(all, []int{99, 5, 1})
+
+	specs.Expect(set.Rank(0), 0)
+	specs.Expect(set.Rank(5), 2)
+	specs.Expect(set.Rank(98), 2)
+	specs.Expect(set.Rank(1000), 3)
+	i, ok := set.Select(2)
+	specs.Expect(i, 99)
+	specs.Expect(ok, true)
+	_, ok = set.Select(3)
+	specs.Expect(ok, false)
+}
+
+func TestArraySetEqual(t *testing.T) {
+	specs := specs.New(t)
+
+	setA := NewArraySet(0).Add(1, 2)
+	setB := NewArraySet(0).Add(2, 1, 1)
+	setC := NewArraySet(0).Add(1, 3)
+
+	specs.Expect(setA.Equal(setB), true)
+	specs.Expect(setA.Equal(setC), false)
+	specs.Expect(setA.Equal(NewHashSet(0).Add(1, 2)), true)
+}
+
+func TestArraySetSubsetOf(t *testing.T) {
+	specs := specs.New(t)
+
+	setA := NewArraySet(0).Add(1, 2)
+	setB := NewArraySet(0).Add(1, 2, 3)
+	setC := NewArraySet(0).Add(3, 4, 5)
+
+	specs.Expect(setA.SubsetOf(setB), true)
+	specs.Expect(setA.SubsetOf(setC), false)
+	specs.Expect(setB.SubsetOf(setA), false)
+	specs.Expect(setB.SupersetOf(setA), true)
+}
+
+func TestArraySetUnion(t *testing.T) {
+	specs := specs.New(t)
+
+	setA := NewArraySet(0).Add(1, 2)
+	setB := NewArraySet(0).Add(3, 4)
+	setC := NewHashSet(0).Add(1, 99)
+
+	specs.Expect(setA.Union(setB).All(), []int{1, 2, 3, 4})
+	specs.Expect(setA.Union(setC).All(), []int{1, 2, 99})
+}
+
+func TestArraySetIntersection(t *testing.T) {
+	specs := specs.New(t)
+
+	setA := NewArraySet(0).Add(1, 2)
+	setB := NewArraySet(0).Add(1, 2, 3)
+	setC := NewArraySet(0).Add(3, 4, 5)
+
+	specs.Expect(setA.Intersection(setB).Equal(setA), true)
+	specs.Expect(setB.Intersection(setC).All(), []int{3})
+	specs.Expect(setC.Intersection(NewHashSet(0).Add(5, 6)).All(), []int{5})
+}
+
+func TestArraySetGallopingIntersection(t *testing.T) {
+	specs := specs.New(t)
+
+	r := rand.New(rand.NewSource(1))
+	small, large := NewArraySet(0), NewArraySet(0)
+	for i := 0; i < 20; i++ {
+		small.Add(r.Intn(10000))
+	}
+	for i := 0; i < 5000; i++ {
+		large.Add(r.Intn(10000))
+	}
+	small.Add(0, 9999)
+	large.Add(0, 9999)
+
+	want := NewHashSet(0).Add(small.All()...).Intersection(NewHashSet(0).Add(large.All()...))
+	specs.Expect(small.Intersection(large).Equal(want), true)
+	specs.Expect(large.Intersection(small).Equal(want), true)
+	specs.Expect(small.IntersectionCount(large), want.Size())
+	specs.Expect(small.Intersects(large), true)
+	specs.Expect(NewArraySet(0).Add(-1).Intersects(large), false)
+
+	for k := 0; k < large.Size(); k++ {
+		v, _ := large.Select(k)
+		specs.Expect(gallop(large.data, v, 0), k)
+		specs.Expect(gallop(large.data, v, k), k)
+	}
+	specs.Expect(gallop(large.data, 10000, 0), large.Size())
+}
+
+func TestArraySetSymetricDifference(t *testing.T) {
+	specs := specs.New(t)
+
+	setA := NewArraySet(0).Add(1, 2, 4)
+	setB := NewArraySet(0).Add(1, 2, 3)
+	setC := NewArraySet(0).Add(3, 4, 5)
+
+	specs.Expect(setA.SymetricDifference(setB).All(), []int{3, 4})
+	specs.Expect(setB.SymetricDifference(setC).All(), []int{1, 2, 4, 5})
+	specs.Expect(setA.Difference(setB).All(), []int{4})
+}
+
+func TestArraySetClone(t *testing.T) {
+	specs := specs.New(t)
+
+	setA := NewArraySet(0).Add(9, 3, 1)
+	setB := setA.Clone()
+	setB.Add(4)
+
+	specs.Expect(setA.All(), []int{1, 3, 9})
+	specs.Expect(setB.All(), []int{1, 3, 4, 9})
+}
+
+func TestArraySetInPlace(t *testing.T) {
+	specs := specs.New(t)
+
+	a := NewArraySet(0).Add(1, 2, 4, 10)
+	for _, b := range []IntSet{NewArraySet(0).Add(2, 3, 10, 15), NewHashSet(20).Add(2, 3, 10, 15)} {
+		specs.Expect(a.Clone().UnionWith(b).Equal(a.Union(b)), true)
+		specs.Expect(a.Clone().IntersectWith(b).Equal(a.Intersection(b)), true)
+		specs.Expect(a.Clone().DifferenceWith(b).Equal(a.Difference(b)), true)
+		specs.Expect(a.Clone().SymmetricDifferenceWith(b).Equal(a.SymetricDifference(b)), true)
+	}
+
+	c := a.Clone()
+	specs.Expect(c.UnionWith(c).Equal(a), true)
+	specs.Expect(c.IntersectWith(c).Equal(a), true)
+	specs.Expect(c.Clone().DifferenceWith(c).Size(), 0)
+	specs.Expect(c.SymmetricDifferenceWith(c).Size(), 0)
+}
+
+// Benchmarks
+
+func BenchmarkArraySetAdd(b *testing.B) {
+	set := NewArraySet(0)
+	for i := 0; i < b.N; i++ {
+		set.Add(rand.Intn(1000))
+	}
+}
+
+func BenchmarkArraySetContains(b *testing.B) {
+	set := NewArraySet(0)
+	for i := 0; i < 500; i++ {
+		set.Add(rand.Intn(1000))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		set.Contains(rand.Intn(1000))
+	}
+}
+
+func BenchmarkArraySetBigUnion(b *testing.B) {
+	setA := NewArraySet(0)
+	setB := NewArraySet(0)
+	for i := 0; i < 5000; i++ {
+		setA.Add(rand.Intn(10000))
+		setB.Add(rand.Intn(10000))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = setA.Union(setB)
+	}
+}
+
+func BenchmarkArraySetBigIntersection(b *testing.B) {
+	setA := NewArraySet(0)
+	setB := NewArraySet(0)
+	for i := 0; i < 5000; i++ {
+		setA.Add(rand.Intn(10000))
+		setB.Add(rand.Intn(10000))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = setA.Intersection(setB)
+	}
+}
+
+func BenchmarkArraySetGallopingIntersection(b *testing.B) {
+	setA := NewArraySet(0)
+	setB := NewArraySet(0)
+	for i := 0; i < 50; i++ {
+		setA.Add(rand.Intn(1000000))
+	}
+	for i := 0; i < 100000; i++ {
+		setB.Add(rand.Intn(1000000))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = setA.Intersection(setB)
+	}
+}
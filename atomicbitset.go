@@ -0,0 +1,171 @@
+package intset
+
+import (
+	"fmt"
+	"iter"
+	"math/bits"
+	"strings"
+	"sync/atomic"
+)
+
+// AtomicBitSet is an integer set backed by a bitset which is safe for
+// concurrent use by multiple goroutines without locking. Each word of the
+// bitset is updated using atomic compare-and-swap. It can hold the integers
+// from 0 to the max given to the constructor.
+//
+// Single integer operations are atomic, but operations which read the whole
+// set, such as Size, Values and Snapshot, are only weakly consistent: they
+// reflect some, but not necessarily all, of the updates made concurrently
+// with them.
+type AtomicBitSet[T Integer] struct {
+	words []atomic.Uint64
+	max   int
+}
+
+// NewAtomicBitSet is the constructor for an AtomicBitSet of ints.
+func NewAtomicBitSet(max int) *AtomicBitSet[int] {
+	return NewAtomicBitSetOf[int](max)
+}
+
+// NewAtomicBitSetOf is the constructor for an AtomicBitSet of any integer type.
+func NewAtomicBitSetOf[T Integer](max int) *AtomicBitSet[T] {
+	return new(AtomicBitSet[T]).init(max)
+}
+
+func (set *AtomicBitSet[T]) init(max int) *AtomicBitSet[T] {
+	set.words = make([]atomic.Uint64, max/64+1)
+	set.max = max
+	return set
+}
+
+// word returns the word holding v and the mask of its bit. The last return
+// value is false if v is outside the range of the set.
+func (set *AtomicBitSet[T]) word(v T) (*atomic.Uint64, uint64, bool) {
+	i, ok := offset(v, 0, set.max+1)
+	if !ok {
+		return nil, 0, false
+	}
+	return &set.words[i/64], 1 << uint(i%64), true
+}
+
+// TestAndSet adds v to the set and returns true if it was already present.
+// It panics if v is outside the range of the set.
+func (set *AtomicBitSet[T]) TestAndSet(v T) bool {
+	w, mask, ok := set.word(v)
+	if !ok {
+		panic(fmt.Sprintf("intset: %v is out of range for AtomicBitSet", v))
+	}
+	for {
+		old := w.Load()
+		if old&mask != 0 {
+			return true
+		}
+		if w.CompareAndSwap(old, old|mask) {
+			return false
+		}
+	}
+}
+
+// TestAndClear removes v from the set and returns true if it was present.
+func (set *AtomicBitSet[T]) TestAndClear(v T) bool {
+	w, mask, ok := set.word(v)
+	if !ok {
+		return false
+	}
+	for {
+		old := w.Load()
+		if old&mask == 0 {
+			return false
+		}
+		if w.CompareAndSwap(old, old&^mask) {
+			return true
+		}
+	}
+}
+
+// Clear the set. It is not atomic with respect to concurrent updates.
+func (set *AtomicBitSet[T]) Clear() *AtomicBitSet[T] {
+	for k := range set.words {
+		set.words[k].Store(0)
+	}
+	return set
+}
+
+// Size returns the number of integers in the set.
+func (set *AtomicBitSet[T]) Size() int {
+	var n int
+	for k := range set.words {
+		n += bits.OnesCount64(set.words[k].Load())
+	}
+	return n
+}
+
+// Add one or more integers to the set. It panics if an integer is outside
+// the range of the set.
+func (set *AtomicBitSet[T]) Add(ints ...T) *AtomicBitSet[T] {
+	for _, i := range ints {
+		set.TestAndSet(i)
+	}
+	return set
+}
+
+// Remove one or more integers from the set.
+func (set *AtomicBitSet[T]) Remove(ints ...T) *AtomicBitSet[T] {
+	for _, i := range ints {
+		set.TestAndClear(i)
+	}
+	return set
+}
+
+// All returns a slice of all the integers in the set in ascending order.
+func (set *AtomicBitSet[T]) All() []T {
+	var all []T
+	for i := range set.Values() {
+		all = append(all, i)
+	}
+	return all
+}
+
+// Values returns an iterator over the integers in the set in ascending order.
+// Each word of the bitset is loaded once, as the iteration reaches it.
+func (set *AtomicBitSet[T]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for k := range set.words {
+			for w := set.words[k].Load(); w != 0; w &= w - 1 {
+				if !yield(T(k*64 + bits.TrailingZeros64(w))) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Contains returns true if all ints are in the set, otherwise false.
+func (set *AtomicBitSet[T]) Contains(ints ...T) bool {
+	for _, i := range ints {
+		w, mask, ok := set.word(i)
+		if !ok || w.Load()&mask == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Snapshot returns a BitSet with the integers in the set.
+func (set *AtomicBitSet[T]) Snapshot() *BitSet[T] {
+	result := &BitSet[T]{words: make([]uint64, len(set.words))}
+	for k := range set.words {
+		result.words[k] = set.words[k].Load()
+	}
+	return result.trim()
+}
+
+// String implements the Stringer interface for AtomicBitSet.
+func (set *AtomicBitSet[T]) String() string {
+	var items []string
+
+	for i := range set.Values() {
+		items = append(items, fmt.Sprintf("%v", i))
+	}
+	return fmt.Sprintf("Set{%s}", strings.Join(items, ", "))
+}
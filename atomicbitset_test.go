@@ -0,0 +1,159 @@
+package intset
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/knakk/specs"
+)
+
+func TestAtomicBitSetTestAndSet(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewAtomicBitSet(1000)
+	specs.Expect(set.TestAndSet(5), false)
+	specs.Expect(set.TestAndSet(5), true)
+	specs.Expect(set.Contains(5), true)
+
+	// every integer must be claimed by exactly one goroutine
+	claimed := make([]int, 8)
+	var wg sync.WaitGroup
+	for g := range claimed {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i <= 1000; i++ {
+				if !set.TestAndSet(i) {
+					claimed[g]++
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	var total int
+	for _, n := range claimed {
+		total += n
+	}
+	specs.Expect(total, 1000)
+	specs.Expect(set.Size(), 1001)
+}
+
+func TestAtomicBitSetTestAndClear(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewAtomicBitSet(1000)
+	for i := 0; i <= 1000; i++ {
+		set.Add(i)
+	}
+	specs.Expect(set.TestAndClear(1001), false)
+
+	cleared := make([]int, 8)
+	var wg sync.WaitGroup
+	for g := range cleared {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i <= 1000; i++ {
+				if set.TestAndClear(i) {
+					cleared[g]++
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	var total int
+	for _, n := range cleared {
+		total += n
+	}
+	specs.Expect(total, 1001)
+	specs.Expect(set.Size(), 0)
+}
+
+func TestAtomicBitSetContains(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewAtomicBitSet(100).Add(1, 2, 3).Remove(2)
+
+	specs.Expect(set.Contains(2), false)
+	specs.Expect(set.Contains(1, 3), true)
+	specs.Expect(set.Contains(1, 2, 3), false)
+	specs.Expect(set.Contains(-1), false)
+	specs.Expect(set.Contains(101), false)
+}
+
+func TestAtomicBitSetAddOutOfRange(t *testing.T) {
+	specs := specs.New(t)
+
+	defer func() {
+		specs.Expect(recover() != nil, true)
+	}()
+	NewAtomicBitSet(100).Add(101)
+}
+
+func TestAtomicBitSetValues(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewAtomicBitSet(100).Add(99, 1, 5, 64)
+	specs.Expect(set.All(), []int{1, 5, 64, 99})
+	specs.Expect(set.String(), "Set{1, 5, 64, 99}")
+	specs.Expect(set.Snapshot().Equal(NewBitSet(0).Add(1, 5, 64, 99)), true)
+
+	var all []int
+	for i := range set.Values() {
+		all = append(all, i)
+		if i >= 5 {
+			break
+		}
+	}
+	specs.Expect(all, []int{1, 5})
+}
+
+func TestAtomicBitSetConcurrentValues(t *testing.T) {
+	specs := specs.New(t)
+
+	// integers below 500 are never removed, so every iteration must see them
+	set := NewAtomicBitSet(1000)
+	for i := 0; i < 500; i++ {
+		set.Add(i)
+	}
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 10000; i++ {
+			set.Add(500 + rand.Intn(500))
+			set.Remove(500 + rand.Intn(500))
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			var n int
+			for j := range set.Values() {
+				if j < 500 {
+					n++
+				}
+			}
+			specs.Expect(n, 500)
+		}
+	}()
+	wg.Wait()
+}
+
+// Benchmarks
+
+func BenchmarkAtomicBitSetTestAndSet(b *testing.B) {
+	set := NewAtomicBitSet(1000)
+	b.RunParallel(func(pb *testing.PB) {
+		r := rand.New(rand.NewSource(rand.Int63()))
+		for pb.Next() {
+			i := r.Intn(1000)
+			if set.TestAndSet(i) {
+				set.TestAndClear(i)
+			}
+		}
+	})
+}
@@ -2,95 +2,455 @@ package intset
 
 import (
 	"fmt"
-	"math/big"
+	"iter"
+	"math/bits"
+	"sort"
 	"strings"
 )
 
-const wordSize = 1 << (^uintptr(0)>>32&1 + ^uintptr(0)>>16&1 + ^uintptr(0)>>8&1 + 3)
+// rankBlockWords is the number of words in each block of the rank index of a
+// BitSet.
+const rankBlockWords = 8
 
-// BitSet is an integer set backed by a bitset implemented using big.Int.
-type BitSet struct {
-	data *big.Int
+// BitSet is an integer set backed by a bitset implemented as a slice of words.
+// It can hold any integer which fits in an int, negative integers included, but
+// as the words span all the integers in the set, they must be less than 2^40
+// apart.
+type BitSet[T Integer] struct {
+	// words holds one bit per integer, with no trailing zero words. Word k
+	// holds the integers from (base+k)*64 to (base+k)*64+63, so the words
+	// only span the integers in the set.
+	words []uint64
+	base  int
+
+	// ranks holds the number of integers before each block of words. It is
+	// built by Rank and Select, and reset when the set is modified.
+	ranks rankIndex
+}
+
+// NewBitSet is the constructor for a BitSet of ints. Max is ignored in this
+// set implementation.
+func NewBitSet(max int) *BitSet[int] {
+	return NewBitSetOf[int](max)
 }
 
-// NewBitSet is the constructor for BitSet. Max is ignored in this set
-// implementation.
-func NewBitSet(max int) *BitSet {
-	return new(BitSet).init(max)
+// NewBitSetOf is the constructor for a BitSet of any integer type. Max is
+// ignored in this set implementation.
+func NewBitSetOf[T Integer](max int) *BitSet[T] {
+	return new(BitSet[T]).init(max)
 }
 
-func (set *BitSet) init(max int) *BitSet {
-	set.data = big.NewInt(0)
+func (set *BitSet[T]) init(max int) *BitSet[T] {
+	set.words = nil
+	set.base = 0
+	set.ranks.reset()
 	return set
 }
 
+// bitPos returns the position of the bit of v: the absolute index of its word,
+// which is negative for negative integers, and the bit in the word. The last
+// return value is false if v does not fit in an int.
+func bitPos[T Integer](v T) (int, uint, bool) {
+	i, ok := toInt(v)
+	return i >> 6, uint(i & 63), ok
+}
+
+// end returns the absolute index of the word after the last word of the set.
+func (set *BitSet[T]) end() int {
+	return set.base + len(set.words)
+}
+
+// word returns the word with absolute index k.
+func (set *BitSet[T]) word(k int) uint64 {
+	if k -= set.base; k >= 0 && k < len(set.words) {
+		return set.words[k]
+	}
+	return 0
+}
+
+// grow makes the words span the absolute word indexes from lo to hi. It panics
+// if the words would span more than maxDense integers.
+func (set *BitSet[T]) grow(lo, hi int) {
+	if len(set.words) == 0 {
+		set.base = lo
+	}
+	first, last := min(lo, set.base), max(hi, set.end()-1)
+	if last-first > maxDense/64 {
+		panic(fmt.Sprintf("intset: BitSet cannot grow to hold the integers from %d to %d", first*64, last*64+63))
+	}
+	if lo < set.base {
+		// prepend at least as many words as the set has, so that adding
+		// integers in descending order takes amortized constant time
+		n := max(set.base-lo, len(set.words))
+		if last-(set.base-n) > maxDense/64 {
+			n = set.base - lo
+		}
+		set.words = append(make([]uint64, n, n+len(set.words)), set.words...)
+		set.base -= n
+	}
+	if n := hi + 1 - set.base; n > len(set.words) {
+		set.words = append(set.words, make([]uint64, n-len(set.words))...)
+	}
+}
+
+// trim removes trailing zero words.
+func (set *BitSet[T]) trim() *BitSet[T] {
+	n := len(set.words)
+	for n > 0 && set.words[n-1] == 0 {
+		n--
+	}
+	set.words = set.words[:n]
+	return set
+}
+
+// spanWith returns the range of absolute word indexes spanned by the words of
+// both sets.
+func (set *BitSet[T]) spanWith(o *BitSet[T]) (int, int) {
+	switch {
+	case len(set.words) == 0:
+		return o.base, o.end()
+	case len(o.words) == 0:
+		return set.base, set.end()
+	}
+	return min(set.base, o.base), max(set.end(), o.end())
+}
+
+// combine returns a new set with the words from lo to hi of set and o combined
+// with op.
+func (set *BitSet[T]) combine(o *BitSet[T], lo, hi int, op func(a, b uint64) uint64) *BitSet[T] {
+	result := &BitSet[T]{base: lo, words: make([]uint64, max(hi-lo, 0))}
+	for k := range result.words {
+		result.words[k] = op(set.word(lo+k), o.word(lo+k))
+	}
+	return result.trim()
+}
+
 // Clear the set.
-func (set *BitSet) Clear() *BitSet {
-	set.data = big.NewInt(0)
+func (set *BitSet[T]) Clear() *BitSet[T] {
+	set.words = set.words[:0]
+	set.ranks.reset()
 	return set
 }
 
 // Size returns the number of integers in the set.
-// Alogrithm stolen from github.com/kisielk/bigset
-func (set *BitSet) Size() int {
-	var l int
-	zero := big.NewInt(0)
-	v := new(big.Int).Set(set.data)
-	for l = 0; v.Cmp(zero) != 0; l++ {
-		vMinusOne := new(big.Int).Sub(v, big.NewInt(1))
-		v.And(v, vMinusOne)
+func (set *BitSet[T]) Size() int {
+	var n int
+	for _, w := range set.words {
+		n += bits.OnesCount64(w)
 	}
-	return l
+	return n
 }
 
-// Add one or more integers to the set.
-func (set *BitSet) Add(ints ...int) *BitSet {
-	for _, i := range ints {
-		set.data.SetBit(set.data, i, 1)
+// Add one or more integers to the set. It panics if an integer does not fit
+// in an int.
+func (set *BitSet[T]) Add(ints ...T) *BitSet[T] {
+	for _, v := range ints {
+		k, b, ok := bitPos(v)
+		if !ok {
+			panic(fmt.Sprintf("intset: %v is out of range for BitSet", v))
+		}
+		set.grow(k, k)
+		set.words[k-set.base] |= 1 << b
 	}
+	set.ranks.reset()
 	return set
 }
 
 // Remove one or more integers from the set.
-func (set *BitSet) Remove(ints ...int) *BitSet {
-	for _, i := range ints {
-		set.data.SetBit(set.data, i, 0)
+func (set *BitSet[T]) Remove(ints ...T) *BitSet[T] {
+	for _, v := range ints {
+		if k, b, ok := bitPos(v); ok && k >= set.base && k < set.end() {
+			set.words[k-set.base] &^= 1 << b
+		}
+	}
+	set.ranks.reset()
+	return set.trim()
+}
+
+// AddRange adds the integers from lo to hi, inclusive, to the set. It panics
+// if lo or hi does not fit in an int.
+func (set *BitSet[T]) AddRange(lo, hi T) *BitSet[T] {
+	i, j := set.checkRange(lo, hi)
+	if i > j {
+		return set
+	}
+	set.grow(i>>6, j>>6)
+	for k := i >> 6; k <= j>>6; k++ {
+		set.words[k-set.base] |= rangeMask(k, i, j)
 	}
+	set.ranks.reset()
 	return set
 }
 
+// RemoveRange removes the integers from lo to hi, inclusive, from the set.
+func (set *BitSet[T]) RemoveRange(lo, hi T) *BitSet[T] {
+	if hi < lo || len(set.words) == 0 {
+		return set
+	}
+	i, ok := toInt(lo)
+	if !ok {
+		return set
+	}
+	i = max(i, set.base*64)
+	j, ok := toInt(hi)
+	if !ok || j > (set.end()-1)*64+63 {
+		j = (set.end()-1)*64 + 63
+	}
+	if i > j {
+		return set
+	}
+	for k := i >> 6; k <= j>>6; k++ {
+		set.words[k-set.base] &^= rangeMask(k, i, j)
+	}
+	set.ranks.reset()
+	return set.trim()
+}
+
+// FlipRange adds the integers from lo to hi, inclusive, which are not in the
+// set, and removes those which are. It panics if lo or hi does not fit in an
+// int.
+func (set *BitSet[T]) FlipRange(lo, hi T) *BitSet[T] {
+	i, j := set.checkRange(lo, hi)
+	if i > j {
+		return set
+	}
+	set.grow(i>>6, j>>6)
+	for k := i >> 6; k <= j>>6; k++ {
+		set.words[k-set.base] ^= rangeMask(k, i, j)
+	}
+	set.ranks.reset()
+	return set.trim()
+}
+
+// checkRange returns lo and hi as ints, or panics if they are out of range.
+func (set *BitSet[T]) checkRange(lo, hi T) (int, int) {
+	i, ok := toInt(lo)
+	if !ok {
+		panic(fmt.Sprintf("intset: %v is out of range for BitSet", lo))
+	}
+	j, ok := toInt(hi)
+	if !ok {
+		panic(fmt.Sprintf("intset: %v is out of range for BitSet", hi))
+	}
+	return i, j
+}
+
 // All returns a slice of all the integers in the set in ascending order.
-func (set *BitSet) All() []int {
-	var all []int
-	for i, w := range set.data.Bits() {
-		for j, z := 0, w; z != 0; j, z = j+1, z>>1 {
-			if z&1 != 0 {
-				all = append(all, i*wordSize+j)
-			}
+func (set *BitSet[T]) All() []T {
+	all := make([]T, 0, set.Size())
+	for k, w := range set.words {
+		for w != 0 {
+			all = append(all, T((set.base+k)*64+bits.TrailingZeros64(w)))
+			w &= w - 1
 		}
 	}
 	return all
 }
 
+// Values returns an iterator over the integers in the set in ascending order.
+func (set *BitSet[T]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for k, w := range set.words {
+			for w != 0 {
+				if !yield(T((set.base+k)*64 + bits.TrailingZeros64(w))) {
+					return
+				}
+				w &= w - 1
+			}
+		}
+	}
+}
+
+// Backward returns an iterator over the integers in the set in descending
+// order.
+func (set *BitSet[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for k := len(set.words) - 1; k >= 0; k-- {
+			for w := set.words[k]; w != 0; {
+				j := 63 - bits.LeadingZeros64(w)
+				if !yield(T((set.base+k)*64 + j)) {
+					return
+				}
+				w &^= 1 << uint(j)
+			}
+		}
+	}
+}
+
+// Min returns the smallest integer in the set. The second return value is
+// false if the set is empty.
+func (set *BitSet[T]) Min() (T, bool) {
+	for k, w := range set.words {
+		if w != 0 {
+			return T((set.base+k)*64 + bits.TrailingZeros64(w)), true
+		}
+	}
+	return 0, false
+}
+
+// Max returns the largest integer in the set. The second return value is false
+// if the set is empty.
+func (set *BitSet[T]) Max() (T, bool) {
+	if k := len(set.words) - 1; k >= 0 {
+		return T((set.base+k)*64 + 63 - bits.LeadingZeros64(set.words[k])), true
+	}
+	return 0, false
+}
+
+// NextAfter returns the smallest integer in the set which is greater than v.
+// The second return value is false if there is no such integer.
+func (set *BitSet[T]) NextAfter(v T) (T, bool) {
+	i, ok := toInt(v)
+	if !ok {
+		return 0, false
+	}
+	k := i>>6 - set.base
+	if k < 0 {
+		return set.Min()
+	}
+	if k >= len(set.words) {
+		return 0, false
+	}
+	// clear the bits up to and including i
+	w := set.words[k] & (^uint64(0) << uint(i&63) << 1)
+	for {
+		if w != 0 {
+			return T((set.base+k)*64 + bits.TrailingZeros64(w)), true
+		}
+		if k++; k >= len(set.words) {
+			return 0, false
+		}
+		w = set.words[k]
+	}
+}
+
+// PrevBefore returns the largest integer in the set which is less than v. The
+// second return value is false if there is no such integer.
+func (set *BitSet[T]) PrevBefore(v T) (T, bool) {
+	i, ok := toInt(v)
+	if !ok {
+		return set.Max()
+	}
+	k := i>>6 - set.base
+	if k >= len(set.words) {
+		return set.Max()
+	}
+	if k < 0 {
+		return 0, false
+	}
+	// keep the bits below i
+	w := set.words[k] & (1<<uint(i&63) - 1)
+	for {
+		if w != 0 {
+			return T((set.base+k)*64 + 63 - bits.LeadingZeros64(w)), true
+		}
+		if k--; k < 0 {
+			return 0, false
+		}
+		w = set.words[k]
+	}
+}
+
 // Contains returns true if all ints are in the set, otherwise false.
-func (set *BitSet) Contains(ints ...int) bool {
-	for _, i := range ints {
-		if set.data.Bit(i) != uint(1) {
+func (set *BitSet[T]) Contains(ints ...T) bool {
+	for _, v := range ints {
+		k, b, ok := bitPos(v)
+		if !ok || set.word(k)&(1<<b) == 0 {
 			return false
 		}
 	}
 	return true
 }
 
+// buildRanks builds the rank index: the number of integers before each block
+// of words.
+func (set *BitSet[T]) buildRanks() []int {
+	ranks := make([]int, (len(set.words)+rankBlockWords-1)/rankBlockWords)
+	var n int
+	for k, w := range set.words {
+		if k%rankBlockWords == 0 {
+			ranks[k/rankBlockWords] = n
+		}
+		n += bits.OnesCount64(w)
+	}
+	return ranks
+}
+
+// Rank returns the number of integers in the set which are less than or equal
+// to v.
+//
+// Rank and Select build an index which is reset when the set is modified, so
+// they are fastest when there are many queries between modifications. The
+// index is built safely by concurrent readers.
+func (set *BitSet[T]) Rank(v T) int {
+	i, ok := toInt(v)
+	if !ok {
+		return set.Size()
+	}
+	k := i>>6 - set.base
+	if k < 0 {
+		return 0
+	}
+	if k >= len(set.words) {
+		return set.Size()
+	}
+	n := set.ranks.get(set.buildRanks)[k/rankBlockWords]
+	for j := k - k%rankBlockWords; j < k; j++ {
+		n += bits.OnesCount64(set.words[j])
+	}
+	return n + bits.OnesCount64(set.words[k]&(^uint64(0)>>uint(63-i&63)))
+}
+
+// Select returns the k-th smallest integer in the set, counting from 0. The
+// second return value is false if k is not less than the size of the set.
+func (set *BitSet[T]) Select(k int) (T, bool) {
+	if k < 0 {
+		return 0, false
+	}
+	ranks := set.ranks.get(set.buildRanks)
+	b := sort.Search(len(ranks), func(b int) bool { return ranks[b] > k }) - 1
+	if b < 0 {
+		return 0, false
+	}
+	k -= ranks[b]
+	for j := b * rankBlockWords; j < len(set.words); j++ {
+		if n := bits.OnesCount64(set.words[j]); k >= n {
+			k -= n
+			continue
+		}
+		return T((set.base+j)*64 + selectBit(set.words[j], k)), true
+	}
+	return 0, false
+}
+
 // Equal checks if two sets both contains all the same items.
-func (set *BitSet) Equal(other *BitSet) bool {
-	return set.data.Cmp(other.data) == 0
+func (set *BitSet[T]) Equal(other Set[T]) bool {
+	o, ok := other.(*BitSet[T])
+	if !ok {
+		return set.Size() == other.Size() && set.SubsetOf(other)
+	}
+	lo, hi := set.spanWith(o)
+	for k := lo; k < hi; k++ {
+		if set.word(k) != o.word(k) {
+			return false
+		}
+	}
+	return true
 }
 
 // SubsetOf checks if all items in set are also present in other set.
-func (set *BitSet) SubsetOf(other *BitSet) bool {
-	for _, i := range set.All() {
-		if !other.Contains(i) {
+func (set *BitSet[T]) SubsetOf(other Set[T]) bool {
+	o, ok := other.(*BitSet[T])
+	if !ok {
+		for _, i := range set.All() {
+			if !other.Contains(i) {
+				return false
+			}
+		}
+		return true
+	}
+	for k, w := range set.words {
+		if w&^o.word(set.base+k) != 0 {
 			return false
 		}
 	}
@@ -98,48 +458,328 @@ func (set *BitSet) SubsetOf(other *BitSet) bool {
 }
 
 // SupersetOf checks if a set is a superset of another set.
-func (set *BitSet) SupersetOf(other *BitSet) bool {
+func (set *BitSet[T]) SupersetOf(other Set[T]) bool {
 	return other.SubsetOf(set)
 }
 
 // Union returns a new set which is the union of two sets.
-func (set *BitSet) Union(other *BitSet) *BitSet {
-	result := NewBitSet(0)
-	result.data.Or(set.data, other.data)
-	return result
+func (set *BitSet[T]) Union(other Set[T]) *BitSet[T] {
+	o, ok := other.(*BitSet[T])
+	if !ok {
+		return set.Clone().Add(other.All()...)
+	}
+	lo, hi := set.spanWith(o)
+	return set.combine(o, lo, hi, func(a, b uint64) uint64 { return a | b })
 }
 
 // Intersection returns a new set with integers common to both sets.
-func (set *BitSet) Intersection(other *BitSet) *BitSet {
-	result := NewBitSet(0)
-	result.data.And(set.data, other.data)
-	return result
+func (set *BitSet[T]) Intersection(other Set[T]) *BitSet[T] {
+	o, ok := other.(*BitSet[T])
+	if !ok {
+		result := NewBitSetOf[T](0)
+		for _, i := range set.All() {
+			if other.Contains(i) {
+				result.Add(i)
+			}
+		}
+		return result
+	}
+	lo, hi := max(set.base, o.base), min(set.end(), o.end())
+	return set.combine(o, lo, hi, func(a, b uint64) uint64 { return a & b })
 }
 
 // Difference returns a new set with the integers in set which are not in other.
-func (set *BitSet) Difference(other *BitSet) *BitSet {
-	result := NewBitSet(0)
-	result.data.And(set.data, new(big.Int).Not(other.data))
-	return result
+func (set *BitSet[T]) Difference(other Set[T]) *BitSet[T] {
+	o, ok := other.(*BitSet[T])
+	if !ok {
+		result := NewBitSetOf[T](0)
+		for _, i := range set.All() {
+			if !other.Contains(i) {
+				result.Add(i)
+			}
+		}
+		return result
+	}
+	return set.combine(o, set.base, set.end(), func(a, b uint64) uint64 { return a &^ b })
 }
 
 // SymetricDifference returns a new set with the integers in current and other,
 // but not in both.
-func (set *BitSet) SymetricDifference(other *BitSet) *BitSet {
-	result := NewBitSet(0)
-	result.data.Xor(set.data, other.data)
-	return result
+func (set *BitSet[T]) SymetricDifference(other Set[T]) *BitSet[T] {
+	o, ok := other.(*BitSet[T])
+	if !ok {
+		result := set.Clone()
+		for _, i := range other.All() {
+			if set.Contains(i) {
+				result.Remove(i)
+			} else {
+				result.Add(i)
+			}
+		}
+		return result
+	}
+	lo, hi := set.spanWith(o)
+	return set.combine(o, lo, hi, func(a, b uint64) uint64 { return a ^ b })
+}
+
+// UnionWith adds the integers in other to the set. It is the in-place variant
+// of Union.
+func (set *BitSet[T]) UnionWith(other Set[T]) *BitSet[T] {
+	o, ok := other.(*BitSet[T])
+	if !ok {
+		for i := range other.Values() {
+			set.Add(i)
+		}
+		return set
+	}
+	if len(o.words) == 0 {
+		return set
+	}
+	set.grow(o.base, o.end()-1)
+	for k, w := range o.words {
+		set.words[o.base+k-set.base] |= w
+	}
+	set.ranks.reset()
+	return set
+}
+
+// IntersectWith removes the integers which are not in other from the set. It
+// is the in-place variant of Intersection.
+func (set *BitSet[T]) IntersectWith(other Set[T]) *BitSet[T] {
+	if o, ok := other.(*BitSet[T]); ok {
+		for k := range set.words {
+			set.words[k] &= o.word(set.base + k)
+		}
+	} else {
+		for k, w := range set.words {
+			for ; w != 0; w &= w - 1 {
+				b := bits.TrailingZeros64(w)
+				if !other.Contains(T((set.base+k)*64 + b)) {
+					set.words[k] &^= 1 << uint(b)
+				}
+			}
+		}
+	}
+	set.ranks.reset()
+	return set.trim()
+}
+
+// DifferenceWith removes the integers in other from the set. It is the
+// in-place variant of Difference.
+func (set *BitSet[T]) DifferenceWith(other Set[T]) *BitSet[T] {
+	o, ok := other.(*BitSet[T])
+	if !ok {
+		for i := range other.Values() {
+			set.Remove(i)
+		}
+		return set
+	}
+	for k := range set.words {
+		set.words[k] &^= o.word(set.base + k)
+	}
+	set.ranks.reset()
+	return set.trim()
+}
+
+// SymmetricDifferenceWith adds the integers in other which are not in the set,
+// and removes those which are. It is the in-place variant of
+// SymetricDifference.
+func (set *BitSet[T]) SymmetricDifferenceWith(other Set[T]) *BitSet[T] {
+	o, ok := other.(*BitSet[T])
+	if !ok {
+		for i := range other.Values() {
+			if set.Contains(i) {
+				set.Remove(i)
+			} else {
+				set.Add(i)
+			}
+		}
+		return set
+	}
+	if len(o.words) == 0 {
+		return set
+	}
+	set.grow(o.base, o.end()-1)
+	for k, w := range o.words {
+		set.words[o.base+k-set.base] ^= w
+	}
+	set.ranks.reset()
+	return set.trim()
+}
+
+// IntersectionCount returns the number of integers common to both sets,
+// without building the intersection.
+func (set *BitSet[T]) IntersectionCount(other Set[T]) int {
+	o, ok := other.(*BitSet[T])
+	if !ok {
+		return intersectionCount[T](set, other)
+	}
+	var n int
+	for k := max(set.base, o.base); k < min(set.end(), o.end()); k++ {
+		n += bits.OnesCount64(set.words[k-set.base] & o.words[k-o.base])
+	}
+	return n
+}
+
+// UnionCount returns the number of integers in the union of two sets, without
+// building the union.
+func (set *BitSet[T]) UnionCount(other Set[T]) int {
+	o, ok := other.(*BitSet[T])
+	if !ok {
+		return set.Size() + other.Size() - intersectionCount[T](set, other)
+	}
+	var n int
+	lo, hi := set.spanWith(o)
+	for k := lo; k < hi; k++ {
+		n += bits.OnesCount64(set.word(k) | o.word(k))
+	}
+	return n
+}
+
+// DifferenceCount returns the number of integers in set which are not in
+// other, without building the difference.
+func (set *BitSet[T]) DifferenceCount(other Set[T]) int {
+	o, ok := other.(*BitSet[T])
+	if !ok {
+		return set.Size() - intersectionCount[T](set, other)
+	}
+	var n int
+	for k, w := range set.words {
+		n += bits.OnesCount64(w &^ o.word(set.base+k))
+	}
+	return n
+}
+
+// SymmetricDifferenceCount returns the number of integers in current and
+// other, but not in both, without building the symmetric difference.
+func (set *BitSet[T]) SymmetricDifferenceCount(other Set[T]) int {
+	o, ok := other.(*BitSet[T])
+	if !ok {
+		return set.Size() + other.Size() - 2*intersectionCount[T](set, other)
+	}
+	var n int
+	lo, hi := set.spanWith(o)
+	for k := lo; k < hi; k++ {
+		n += bits.OnesCount64(set.word(k) ^ o.word(k))
+	}
+	return n
+}
+
+// Intersects returns true if the sets have at least one integer in common.
+func (set *BitSet[T]) Intersects(other Set[T]) bool {
+	o, ok := other.(*BitSet[T])
+	if !ok {
+		return intersects[T](set, other)
+	}
+	for k := max(set.base, o.base); k < min(set.end(), o.end()); k++ {
+		if set.words[k-set.base]&o.words[k-o.base] != 0 {
+			return true
+		}
+	}
+	return false
 }
 
 // Clone returns a new set which is a clone of current set.
-func (set *BitSet) Clone() *BitSet {
-	result := NewBitSet(0)
-	result.data.Set(set.data)
-	return result
+func (set *BitSet[T]) Clone() *BitSet[T] {
+	// leave out leading zero words
+	k := 0
+	for k < len(set.words) && set.words[k] == 0 {
+		k++
+	}
+	return &BitSet[T]{words: append([]uint64(nil), set.words[k:]...), base: set.base + k}
+}
+
+// Insert adds one or more integers to the set. It is the Set adapter for Add.
+func (set *BitSet[T]) Insert(ints ...T) {
+	set.Add(ints...)
+}
+
+// Delete removes one or more integers from the set. It is the Set adapter
+// for Remove.
+func (set *BitSet[T]) Delete(ints ...T) {
+	set.Remove(ints...)
+}
+
+// CloneSet is the Set adapter for Clone.
+func (set *BitSet[T]) CloneSet() Set[T] {
+	return set.Clone()
+}
+
+// UnionSet is the Set adapter for Union.
+func (set *BitSet[T]) UnionSet(other Set[T]) Set[T] {
+	return set.Union(other)
+}
+
+// IntersectionSet is the Set adapter for Intersection.
+func (set *BitSet[T]) IntersectionSet(other Set[T]) Set[T] {
+	return set.Intersection(other)
+}
+
+// DifferenceSet is the Set adapter for Difference.
+func (set *BitSet[T]) DifferenceSet(other Set[T]) Set[T] {
+	return set.Difference(other)
+}
+
+// SymetricDifferenceSet is the Set adapter for SymetricDifference.
+func (set *BitSet[T]) SymetricDifferenceSet(other Set[T]) Set[T] {
+	return set.SymetricDifference(other)
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (set *BitSet[T]) MarshalBinary() ([]byte, error) {
+	return marshalBinary(tagBitSet, set.All()), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface. The
+// data may have been marshalled from any set type.
+func (set *BitSet[T]) UnmarshalBinary(data []byte) error {
+	ints, err := unmarshalBinary[T](data)
+	if err != nil {
+		return err
+	}
+	return set.load(ints)
+}
+
+// MarshalJSON implements the json.Marshaler interface. The set is marshalled
+// as an array of integers in ascending order.
+func (set *BitSet[T]) MarshalJSON() ([]byte, error) {
+	return marshalJSON(set.All()), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It accepts an array
+// of integers or a string in the form produced by MarshalText.
+func (set *BitSet[T]) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, set.load, expanded(set.load))
+}
+
+// MarshalText implements the encoding.TextMarshaler interface. The set is
+// marshalled as a comma separated list of integers and ranges of integers in
+// ascending order, e.g. "1-5,9".
+func (set *BitSet[T]) MarshalText() ([]byte, error) {
+	return marshalText(set.All()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface. It accepts
+// the form produced by MarshalText as well as the one produced by String.
+func (set *BitSet[T]) UnmarshalText(text []byte) error {
+	return unmarshalText(text, expanded(set.load))
+}
+
+// load replaces the contents of the set with the given integers. It returns an
+// error if the integers are too far apart for the set to hold.
+func (set *BitSet[T]) load(ints []T) error {
+	if err := checkInts("BitSet", ints); err != nil {
+		return err
+	}
+	if _, _, err := denseSpan("BitSet", 0, -1, ints); err != nil {
+		return err
+	}
+	set.Clear().Add(ints...)
+	return nil
 }
 
 // String implements the Stringer interface for BitSet.
-func (set *BitSet) String() string {
+func (set *BitSet[T]) String() string {
 	items := make([]string, 0, set.Size())
 
 	for _, i := range set.All() {
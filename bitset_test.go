@@ -1,7 +1,10 @@
 package intset
 
 import (
+	"fmt"
+	"math"
 	"math/rand"
+	"slices"
 	"testing"
 
 	"github.com/knakk/specs"
@@ -25,6 +28,80 @@ func TestBitSetRemove(t *testing.T) {
 	specs.Expect(set.Contains(1, 3), false)
 }
 
+func TestBitSetRanges(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewBitSet(200).AddRange(10, 140)
+	specs.Expect(set.Size(), 131)
+	specs.Expect(set.Contains(10, 63, 64, 140), true)
+	specs.Expect(set.Contains(9), false)
+	specs.Expect(set.Contains(141), false)
+
+	set.RemoveRange(20, 129)
+	specs.Expect(set.Size(), 21)
+	specs.Expect(set.Contains(19, 130), true)
+	specs.Expect(set.Contains(20), false)
+	specs.Expect(set.Contains(129), false)
+
+	set.FlipRange(15, 25)
+	specs.Expect(set.Size(), 22)
+	specs.Expect(set.Contains(14, 20, 25), true)
+	specs.Expect(set.Contains(15), false)
+	specs.Expect(set.Contains(26), false)
+
+	set.RemoveRange(-5, 1000)
+	specs.Expect(set.Size(), 0)
+	set.AddRange(5, 4)
+	specs.Expect(set.Size(), 0)
+}
+
+func TestBitSetNegative(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewBitSet(10).Add(5, -1, -64, -65)
+	specs.Expect(set.Contains(-65, -64, -1, 5), true)
+	specs.Expect(set.Contains(-2), false)
+	specs.Expect(set.All(), []int{-65, -64, -1, 5})
+	specs.Expect(slices.Collect(set.Backward()), []int{5, -1, -64, -65})
+	specs.Expect(set.Rank(-64), 2)
+	specs.Expect(set.Rank(-1000), 0)
+	i, _ := set.Select(2)
+	specs.Expect(i, -1)
+
+	set.AddRange(-300, -200)
+	specs.Expect(set.Size(), 105)
+	set.FlipRange(-210, -190)
+	specs.Expect(set.Contains(-211, -190), true)
+	specs.Expect(set.Contains(-210, -200), false)
+	set.RemoveRange(-1000, -1)
+	specs.Expect(set.All(), []int{5})
+
+	setA := NewBitSet(0).Add(-1000, 3)
+	setB := NewBitSet(0).Add(-5, 3, 1000)
+	specs.Expect(setA.Union(setB).All(), []int{-1000, -5, 3, 1000})
+	specs.Expect(setA.Intersection(setB).All(), []int{3})
+	specs.Expect(setA.SymetricDifference(setB).All(), []int{-1000, -5, 1000})
+	specs.Expect(setB.Clone().DifferenceWith(setA).All(), []int{-5, 1000})
+}
+
+func TestBitSetBounds(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewBitSet(0).Add(math.MaxInt, math.MaxInt-64)
+	specs.Expect(set.All(), []int{math.MaxInt - 64, math.MaxInt})
+	set = NewBitSet(0).Add(math.MinInt, math.MinInt+64)
+	specs.Expect(set.All(), []int{math.MinInt, math.MinInt + 64})
+	set.AddRange(math.MinInt, math.MinInt+200)
+	specs.Expect(set.Size(), 201)
+
+	specs.Expect(NewBitSet(0).UnmarshalJSON([]byte("[-9223372036854775808,9223372036854775807]")) != nil, true)
+
+	defer func() {
+		specs.Expect(fmt.Sprint(recover()), "intset: BitSet cannot grow to hold the integers from -9223372036854775808 to 9223372036854775807")
+	}()
+	NewBitSet(0).Add(math.MinInt, math.MaxInt)
+}
+
 func TestBitSetContains(t *testing.T) {
 	specs := specs.New(t)
 
@@ -65,6 +142,88 @@ func TestBitSetAll(t *testing.T) {
 	specs.Expect(all[2], 99)
 }
 
+func TestBitSetValues(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewBitSet(10).Add(99, 1, 5)
+	var all []int
+	for i := range set.Values() {
+		all = append(all, i)
+	}
+	specs.Expect(all, []int{1, 5, 99})
+
+	all = nil
+	for i := range set.Values() {
+		all = append(all, i)
+		if i >= 5 {
+			break
+		}
+	}
+	specs.Expect(all, []int{1, 5})
+}
+
+func TestBitSetBackward(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewBitSet(10).Add(99, 1, 5)
+	var all []int
+	for i := range set.Backward() {
+		all = append(all, i)
+	}
+	specs.Expect(all, []int{99, 5, 1})
+
+	all = nil
+	for i := range set.Backward() {
+		all = append(all, i)
+		if i <= 5 {
+			break
+		}
+	}
+	specs.Expect(all, []int{99, 5})
+}
+
+func TestBitSetRank(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewBitSet(0).Add(99, 1, 5, 64, 1000)
+
+	specs.Expect(set.Rank(-1), 0)
+	specs.Expect(set.Rank(0), 0)
+	specs.Expect(set.Rank(1), 1)
+	specs.Expect(set.Rank(63), 2)
+	specs.Expect(set.Rank(64), 3)
+	specs.Expect(set.Rank(999), 4)
+	specs.Expect(set.Rank(1000), 5)
+	specs.Expect(set.Rank(100000), 5)
+
+	set.Remove(5)
+	specs.Expect(set.Rank(64), 2)
+}
+
+func TestBitSetSelect(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewBitSet(0)
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		set.Add(r.Intn(100000))
+	}
+	for k, want := range set.All() {
+		i, ok := set.Select(k)
+		specs.Expect(ok, true)
+		specs.Expect(i, want)
+		specs.Expect(set.Rank(i), k+1)
+	}
+	_, ok := set.Select(set.Size())
+	specs.Expect(ok, false)
+	_, ok = set.Select(-1)
+	specs.Expect(ok, false)
+
+	set.Remove(set.All()[0])
+	i, _ := set.Select(0)
+	specs.Expect(i, set.All()[0])
+}
+
 func TestBitSetEqual(t *testing.T) {
 	specs := specs.New(t)
 
@@ -140,6 +299,41 @@ func TestBitSetClone(t *testing.T) {
 	specs.Expect(setB.Equal(setA), true)
 }
 
+func TestBitSetInPlace(t *testing.T) {
+	specs := specs.New(t)
+
+	a := NewBitSet(20).Add(1, 2, 4, 10)
+	for _, b := range []IntSet{NewBitSet(20).Add(2, 3, 10, 15), NewHashSet(20).Add(2, 3, 10, 15)} {
+		specs.Expect(a.Clone().UnionWith(b).Equal(a.Union(b)), true)
+		specs.Expect(a.Clone().IntersectWith(b).Equal(a.Intersection(b)), true)
+		specs.Expect(a.Clone().DifferenceWith(b).Equal(a.Difference(b)), true)
+		specs.Expect(a.Clone().SymmetricDifferenceWith(b).Equal(a.SymetricDifference(b)), true)
+	}
+
+	c := a.Clone()
+	specs.Expect(c.UnionWith(c).Equal(a), true)
+	specs.Expect(c.IntersectWith(c).Equal(a), true)
+	specs.Expect(c.Clone().DifferenceWith(c).Size(), 0)
+	specs.Expect(c.SymmetricDifferenceWith(c).Size(), 0)
+}
+
+func TestBitSetCountsAllocations(t *testing.T) {
+	specs := specs.New(t)
+
+	a := NewBitSet(0).AddRange(0, 1000)
+	b := NewBitSet(0).AddRange(500, 2000)
+	allocs := testing.AllocsPerRun(10, func() {
+		a.IntersectionCount(b)
+		a.UnionCount(b)
+		a.DifferenceCount(b)
+		a.SymmetricDifferenceCount(b)
+		a.Intersects(b)
+	})
+	specs.Expect(allocs, 0.0)
+	specs.Expect(a.IntersectionCount(b), 501)
+	specs.Expect(b.DifferenceCount(a), 1000)
+}
+
 // Benchmarks
 
 func BenchmarkBitSetAdd(b *testing.B) {
@@ -279,3 +473,54 @@ func BenchmarkBitSetBigSymetricDifference(b *testing.B) {
 		_ = setA.SymetricDifference(setB).SymetricDifference(setC)
 	}
 }
+
+func BenchmarkBitSetSize(b *testing.B) {
+	set := NewBitSet(10000)
+	for i := 0; i < 5000; i++ {
+		set.Add(rand.Intn(10000))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		set.Size()
+	}
+}
+
+func BenchmarkBitSetAll(b *testing.B) {
+	set := NewBitSet(10000)
+	for i := 0; i < 5000; i++ {
+		set.Add(rand.Intn(10000))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = set.All()
+	}
+}
+
+func BenchmarkBitSetValues(b *testing.B) {
+	set := NewBitSet(10000)
+	for i := 0; i < 5000; i++ {
+		set.Add(rand.Intn(10000))
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for range set.Values() {
+		}
+	}
+}
+
+func BenchmarkBitSetAddRange(b *testing.B) {
+	set := NewBitSet(10000000)
+	for i := 0; i < b.N; i++ {
+		set.AddRange(0, 10000000)
+	}
+}
+
+func BenchmarkBitSetUnionWith(b *testing.B) {
+	set := NewBitSet(100000)
+	other := NewBitSet(100000).AddRange(0, 100000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		set.UnionWith(other)
+	}
+}
@@ -2,44 +2,99 @@ package intset
 
 import (
 	"fmt"
+	"iter"
 	"strings"
 )
 
 // BriggsSet is an integer set implementeation based on Briggs/Torczon paper
-// "An Efficient Representation for Sparse Sets" from 1993
-type BriggsSet struct {
-	dense  []int
+// "An Efficient Representation for Sparse Sets" from 1993. Its storage
+// initially holds the integers from the min to the max given to the
+// constructor, or from 0 to max for NewBriggsSet, and grows when integers
+// outside that range are added, unless the set is limited with Limit. Adding
+// an integer more than 2^40 integers away from the others panics.
+type BriggsSet[T Integer] struct {
+	dense  []T
 	sparse []int
+	base   int // the integer at sparse[0]
 	size   int
+	limit  limit
 }
 
-// NewBriggsSet is the constructor for BriggsSet.
-func NewBriggsSet(max int) *BriggsSet {
-	return new(BriggsSet).init(max)
+// NewBriggsSet is the constructor for a BriggsSet of ints.
+func NewBriggsSet(max int) *BriggsSet[int] {
+	return NewBriggsSetOf[int](max)
 }
 
-func (set *BriggsSet) init(max int) *BriggsSet {
-	set.sparse = make([]int, max+1)
-	set.dense = make([]int, max+1)
+// NewBriggsSetOf is the constructor for a BriggsSet of any integer type.
+func NewBriggsSetOf[T Integer](max int) *BriggsSet[T] {
+	return new(BriggsSet[T]).init(0, max)
+}
+
+// NewBriggsSetRange is the constructor for a BriggsSet of ints which can hold
+// the integers from min to max, inclusive.
+func NewBriggsSetRange(min, max int) *BriggsSet[int] {
+	return NewBriggsSetRangeOf[int](min, max)
+}
+
+// NewBriggsSetRangeOf is the constructor for a BriggsSet of any integer type
+// which can hold the integers from min to max, inclusive.
+func NewBriggsSetRangeOf[T Integer](min, max int) *BriggsSet[T] {
+	return new(BriggsSet[T]).init(min, max)
+}
+
+func (set *BriggsSet[T]) init(min, max int) *BriggsSet[T] {
+	set.sparse = make([]int, max-min+1)
+	set.dense = make([]T, max-min+1)
+	set.base = min
+	set.size = 0
+	return set
+}
+
+// Limit restricts the set to the integers from lo to hi, inclusive. Adding an
+// integer outside the limit panics, instead of growing the set to hold it,
+// while TryAdd returns an error. Limit panics if the set already holds an
+// integer outside the limit.
+func (set *BriggsSet[T]) Limit(lo, hi int) *BriggsSet[T] {
+	if m, ok := set.Min(); ok && int(m) < lo {
+		panic(fmt.Sprintf("intset: %v is out of range for BriggsSet", m))
+	}
+	if m, ok := set.Max(); ok && int(m) > hi {
+		panic(fmt.Sprintf("intset: %v is out of range for BriggsSet", m))
+	}
+	// keep only the part of the storage within the limit
+	i, j := max(lo, set.base), min(hi, set.base+len(set.sparse)-1)
+	if i > j {
+		set.sparse, set.base = nil, lo
+	} else {
+		set.sparse, set.base = set.sparse[i-set.base:j-set.base+1], i
+	}
+	set.limit = limit{lo, hi, true}
 	return set
 }
 
 // Clear the set.
-func (set *BriggsSet) Clear() *BriggsSet {
+func (set *BriggsSet[T]) Clear() *BriggsSet[T] {
 	set.size = 0
 	return set
 }
 
 // Size returns the number of integers in the set.
-func (set *BriggsSet) Size() int {
+func (set *BriggsSet[T]) Size() int {
 	return set.size
 }
 
-// Add one or more integers to the set.
-func (set *BriggsSet) Add(ints ...int) *BriggsSet {
-	for _, i := range ints {
-		if !set.Contains(i) {
-			set.dense[set.size] = i
+// Add one or more integers to the set, growing the set to hold them if
+// needed. It panics if an integer is outside the limit of the set.
+func (set *BriggsSet[T]) Add(ints ...T) *BriggsSet[T] {
+	for _, v := range ints {
+		i, ok := set.pos(v)
+		if !ok {
+			i = checkLimit(set.limit, "BriggsSet", v)
+			set.grow(i, i)
+			i -= set.base
+		}
+		if !set.Contains(v) {
+			set.dense[set.size] = v
 			set.sparse[i] = set.size
 			set.size++
 		}
@@ -48,43 +103,182 @@ func (set *BriggsSet) Add(ints ...int) *BriggsSet {
 }
 
 // Remove one or more integers from the set.
-func (set *BriggsSet) Remove(ints ...int) *BriggsSet {
-	for _, i := range ints {
-		if set.Contains(i) {
+func (set *BriggsSet[T]) Remove(ints ...T) *BriggsSet[T] {
+	for _, v := range ints {
+		if set.Contains(v) {
+			i := int(v) - set.base
 			j := set.dense[set.size-1]
 			set.dense[set.sparse[i]] = j
-			set.sparse[j] = set.sparse[i]
+			set.sparse[int(j)-set.base] = set.sparse[i]
 			set.size--
 		}
 	}
 	return set
 }
 
+// TryAdd adds one or more integers to the set, like Add, but returns an
+// *OutOfRangeError instead of panicking if an integer is outside the limit of
+// the set. The set is left unchanged in that case.
+func (set *BriggsSet[T]) TryAdd(ints ...T) error {
+	if err := checkLimits(set.limit, ints); err != nil {
+		return err
+	}
+	set.Add(ints...)
+	return nil
+}
+
+// TryRemove removes one or more integers from the set, like Remove, but
+// returns an *OutOfRangeError if an integer is outside the limit of the set.
+// The set is left unchanged in that case.
+func (set *BriggsSet[T]) TryRemove(ints ...T) error {
+	if err := checkLimits(set.limit, ints); err != nil {
+		return err
+	}
+	set.Remove(ints...)
+	return nil
+}
+
+// AddRange adds the integers from lo to hi, inclusive, to the set, growing the
+// set to hold them if needed. It panics if lo or hi is outside the limit of
+// the set.
+func (set *BriggsSet[T]) AddRange(lo, hi T) *BriggsSet[T] {
+	i, j := set.checkRange(lo, hi)
+	for ; i <= j; i++ {
+		if v := T(set.base + i); set.sparse[i] >= set.size || set.dense[set.sparse[i]] != v {
+			set.dense[set.size] = v
+			set.sparse[i] = set.size
+			set.size++
+		}
+	}
+	return set
+}
+
+// RemoveRange removes the integers from lo to hi, inclusive, from the set.
+func (set *BriggsSet[T]) RemoveRange(lo, hi T) *BriggsSet[T] {
+	i, j, ok := clampRange(lo, hi, set.base, len(set.sparse))
+	if !ok {
+		return set
+	}
+	if j-i >= set.size {
+		// the set is smaller than the range, so scan the set instead
+		for k := 0; k < set.size; {
+			if p := int(set.dense[k]) - set.base; p >= i && p <= j {
+				set.removeAt(k)
+				continue
+			}
+			k++
+		}
+		return set
+	}
+	for ; i <= j; i++ {
+		set.Remove(T(set.base + i))
+	}
+	return set
+}
+
+// FlipRange adds the integers from lo to hi, inclusive, which are not in the
+// set, and removes those which are, growing the set to hold them if needed. It
+// panics if lo or hi is outside the limit of the set.
+func (set *BriggsSet[T]) FlipRange(lo, hi T) *BriggsSet[T] {
+	i, j := set.checkRange(lo, hi)
+	for ; i <= j; i++ {
+		if v := T(set.base + i); set.Contains(v) {
+			set.Remove(v)
+		} else {
+			set.Add(v)
+		}
+	}
+	return set
+}
+
+// checkRange returns the positions of lo and hi, growing the set to hold them
+// if needed. It panics if lo or hi is outside the limit of the set.
+func (set *BriggsSet[T]) checkRange(lo, hi T) (int, int) {
+	i := checkLimit(set.limit, "BriggsSet", lo)
+	j := checkLimit(set.limit, "BriggsSet", hi)
+	if i <= j {
+		set.grow(i, j)
+	}
+	return i - set.base, j - set.base
+}
+
+// pos returns the position of v in sparse. The second return value is false
+// if v is outside the range of the set.
+func (set *BriggsSet[T]) pos(v T) (int, bool) {
+	return offset(v, set.base, len(set.sparse))
+}
+
 // All returns a slice of all the integers in the set. It makes no guarantee
 // that the integers are in the same order as they where inserted.
-func (set *BriggsSet) All() []int {
-	var all []int
+func (set *BriggsSet[T]) All() []T {
+	var all []T
 	for i := 0; i < set.size; i++ {
 		all = append(all, set.dense[i])
 	}
 	return all
 }
 
-// Contains returns true if all ints are in the set, otherwise false.
-func (set *BriggsSet) Contains(ints ...int) bool {
-	if set.size <= 0 {
-		return false
+// Values returns an iterator over the integers in the set. Like All, it
+// makes no guarantee about the order of the integers.
+func (set *BriggsSet[T]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := 0; i < set.size; i++ {
+			if !yield(set.dense[i]) {
+				return
+			}
+		}
 	}
-	for _, i := range ints {
-		if set.dense[set.sparse[i]] != i {
+}
+
+// Min returns the smallest integer in the set. The second return value is
+// false if the set is empty.
+func (set *BriggsSet[T]) Min() (T, bool) {
+	return minOf(set.Values())
+}
+
+// Max returns the largest integer in the set. The second return value is false
+// if the set is empty.
+func (set *BriggsSet[T]) Max() (T, bool) {
+	return maxOf(set.Values())
+}
+
+// NextAfter returns the smallest integer in the set which is greater than v.
+// The second return value is false if there is no such integer.
+func (set *BriggsSet[T]) NextAfter(v T) (T, bool) {
+	return nextAfter(set.Values(), v)
+}
+
+// PrevBefore returns the largest integer in the set which is less than v. The
+// second return value is false if there is no such integer.
+func (set *BriggsSet[T]) PrevBefore(v T) (T, bool) {
+	return prevBefore(set.Values(), v)
+}
+
+// Contains returns true if all ints are in the set, otherwise false.
+func (set *BriggsSet[T]) Contains(ints ...T) bool {
+	for _, v := range ints {
+		i, ok := set.pos(v)
+		if !ok {
+			return false
+		}
+		if j := set.sparse[i]; j >= set.size || set.dense[j] != v {
 			return false
 		}
 	}
 	return true
 }
 
+// TryContains returns true if all ints are in the set, like Contains, but
+// returns an *OutOfRangeError if an integer is outside the limit of the set.
+func (set *BriggsSet[T]) TryContains(ints ...T) (bool, error) {
+	if err := checkLimits(set.limit, ints); err != nil {
+		return false, err
+	}
+	return set.Contains(ints...), nil
+}
+
 // Equal checks if two sets both contains all the same items.
-func (set *BriggsSet) Equal(other *BriggsSet) bool {
+func (set *BriggsSet[T]) Equal(other Set[T]) bool {
 	if set.Size() != other.Size() {
 		return false
 	}
@@ -97,7 +291,7 @@ func (set *BriggsSet) Equal(other *BriggsSet) bool {
 }
 
 // SubsetOf checks if all items in set are also present in other set.
-func (set *BriggsSet) SubsetOf(other *BriggsSet) bool {
+func (set *BriggsSet[T]) SubsetOf(other Set[T]) bool {
 	for i := 0; i < set.size; i++ {
 		if !other.Contains(set.dense[i]) {
 			return false
@@ -107,47 +301,34 @@ func (set *BriggsSet) SubsetOf(other *BriggsSet) bool {
 }
 
 // SupersetOf checks if a set is a superset of another set.
-func (set *BriggsSet) SupersetOf(other *BriggsSet) bool {
+func (set *BriggsSet[T]) SupersetOf(other Set[T]) bool {
 	return other.SubsetOf(set)
 }
 
 // Union returns a new set which is the union of two sets.
-func (set *BriggsSet) Union(other *BriggsSet) *BriggsSet {
-	result := NewBriggsSet(max(len(set.dense), len(other.dense)))
-	for i := 0; i < set.size; i++ {
-		result.Add(set.dense[i])
-	}
-	for i := 0; i < other.size; i++ {
-		result.Add(other.dense[i])
-	}
-	return result
+func (set *BriggsSet[T]) Union(other Set[T]) *BriggsSet[T] {
+	return set.Clone().UnionWith(other)
 }
 
 // Intersection returns a new set with integers common to both sets.
-func (set *BriggsSet) Intersection(other *BriggsSet) *BriggsSet {
-	result := &BriggsSet{}
+func (set *BriggsSet[T]) Intersection(other Set[T]) *BriggsSet[T] {
+	o, ok := other.(*BriggsSet[T])
 	// always loop over the smallest set
-	if set.Size() < other.Size() {
-		result.init(max(len(set.dense), len(other.dense)))
-		for i := 0; i < set.size; i++ {
-			if other.Contains(set.dense[i]) {
-				result.Add(set.dense[i])
-			}
-		}
-	} else {
-		result.init(max(len(set.dense), len(other.dense)))
-		for i := 0; i < other.size; i++ {
-			if set.Contains(other.dense[i]) {
-				result.Add(other.dense[i])
-			}
+	if ok && o.Size() < set.Size() {
+		return o.Intersection(set)
+	}
+	result := set.empty()
+	for i := 0; i < set.size; i++ {
+		if other.Contains(set.dense[i]) {
+			result.Add(set.dense[i])
 		}
 	}
 	return result
 }
 
 // Difference returns a new set with the integers in set which are not in other.
-func (set *BriggsSet) Difference(other *BriggsSet) *BriggsSet {
-	result := NewBriggsSet(max(len(set.dense), len(other.dense)))
+func (set *BriggsSet[T]) Difference(other Set[T]) *BriggsSet[T] {
+	result := set.empty()
 	for i := 0; i < set.size; i++ {
 		if !other.Contains(set.dense[i]) {
 			result.Add(set.dense[i])
@@ -156,28 +337,265 @@ func (set *BriggsSet) Difference(other *BriggsSet) *BriggsSet {
 	return result
 }
 
+// empty returns a new empty set with the same range and limit as set.
+func (set *BriggsSet[T]) empty() *BriggsSet[T] {
+	result := new(BriggsSet[T]).init(set.base, set.base+len(set.sparse)-1)
+	result.limit = set.limit
+	return result
+}
+
 // SymetricDifference returns a new set with the integers in current and other,
 // but not in both.
-func (set *BriggsSet) SymetricDifference(other *BriggsSet) *BriggsSet {
+func (set *BriggsSet[T]) SymetricDifference(other Set[T]) *BriggsSet[T] {
 	a := set.Difference(other)
-	b := other.Difference(set)
+	b := other.DifferenceSet(set)
 	return a.Union(b)
 }
 
+// UnionWith adds the integers in other to the set, enlarging the range of the
+// set if needed. It is the in-place variant of Union.
+func (set *BriggsSet[T]) UnionWith(other Set[T]) *BriggsSet[T] {
+	set.growFor(other)
+	if o, ok := other.(*BriggsSet[T]); ok {
+		for i := 0; i < o.size; i++ {
+			set.Add(o.dense[i])
+		}
+		return set
+	}
+	for i := range other.Values() {
+		set.Add(i)
+	}
+	return set
+}
+
+// IntersectWith removes the integers which are not in other from the set. It
+// is the in-place variant of Intersection.
+func (set *BriggsSet[T]) IntersectWith(other Set[T]) *BriggsSet[T] {
+	for k := 0; k < set.size; {
+		if !other.Contains(set.dense[k]) {
+			set.removeAt(k)
+			continue
+		}
+		k++
+	}
+	return set
+}
+
+// DifferenceWith removes the integers in other from the set. It is the
+// in-place variant of Difference.
+func (set *BriggsSet[T]) DifferenceWith(other Set[T]) *BriggsSet[T] {
+	if other.Size() < set.size {
+		for i := range other.Values() {
+			set.Remove(i)
+		}
+		return set
+	}
+	for k := 0; k < set.size; {
+		if other.Contains(set.dense[k]) {
+			set.removeAt(k)
+			continue
+		}
+		k++
+	}
+	return set
+}
+
+// SymmetricDifferenceWith adds the integers in other which are not in the set,
+// and removes those which are, enlarging the range of the set if needed. It is
+// the in-place variant of SymetricDifference.
+func (set *BriggsSet[T]) SymmetricDifferenceWith(other Set[T]) *BriggsSet[T] {
+	if o, ok := other.(*BriggsSet[T]); ok && o == set {
+		return set.Clear()
+	}
+	set.growFor(other)
+	for i := range other.Values() {
+		if set.Contains(i) {
+			set.Remove(i)
+		} else {
+			set.Add(i)
+		}
+	}
+	return set
+}
+
+// removeAt removes the integer at position k of dense, by moving the last
+// integer into its place.
+func (set *BriggsSet[T]) removeAt(k int) {
+	last := set.dense[set.size-1]
+	set.dense[k] = last
+	set.sparse[int(last)-set.base] = k
+	set.size--
+}
+
+// growFor enlarges the range of the set to hold the smallest and the largest
+// integer in other. It panics if they are outside the limit of the set.
+func (set *BriggsSet[T]) growFor(other Set[T]) {
+	lo, ok := other.Min()
+	if !ok {
+		return
+	}
+	hi, _ := other.Max()
+	set.grow(checkLimit(set.limit, "BriggsSet", lo), checkLimit(set.limit, "BriggsSet", hi))
+}
+
+// grow enlarges the range of the set to hold the integers from lo to hi, as far
+// as the limit allows. The positions in dense are unaffected, so only sparse
+// has to be shifted.
+func (set *BriggsSet[T]) grow(lo, hi int) {
+	base, n := set.limit.grown("BriggsSet", set.base, len(set.sparse), lo, hi)
+	if base == set.base && n == len(set.sparse) {
+		return
+	}
+	sparse := make([]int, n)
+	if len(set.sparse) > 0 {
+		copy(sparse[set.base-base:], set.sparse)
+	}
+	set.sparse, set.base = sparse, base
+	if n > len(set.dense) {
+		set.dense = append(set.dense, make([]T, n-len(set.dense))...)
+	}
+}
+
+// IntersectionCount returns the number of integers common to both sets,
+// without building the intersection.
+func (set *BriggsSet[T]) IntersectionCount(other Set[T]) int {
+	return intersectionCount[T](set, other)
+}
+
+// UnionCount returns the number of integers in the union of two sets, without
+// building the union.
+func (set *BriggsSet[T]) UnionCount(other Set[T]) int {
+	return set.Size() + other.Size() - set.IntersectionCount(other)
+}
+
+// DifferenceCount returns the number of integers in set which are not in
+// other, without building the difference.
+func (set *BriggsSet[T]) DifferenceCount(other Set[T]) int {
+	return set.Size() - set.IntersectionCount(other)
+}
+
+// SymmetricDifferenceCount returns the number of integers in current and
+// other, but not in both, without building the symmetric difference.
+func (set *BriggsSet[T]) SymmetricDifferenceCount(other Set[T]) int {
+	return set.Size() + other.Size() - 2*set.IntersectionCount(other)
+}
+
+// Intersects returns true if the sets have at least one integer in common.
+func (set *BriggsSet[T]) Intersects(other Set[T]) bool {
+	return intersects[T](set, other)
+}
+
 // Clone returns a new set which is a clone of current set.
-func (set *BriggsSet) Clone() *BriggsSet {
-	result := NewBriggsSet(len(set.dense))
+func (set *BriggsSet[T]) Clone() *BriggsSet[T] {
+	result := set.empty()
 	for i := 0; i < set.size; i++ {
 		result.Add(set.dense[i]) // TODO use copy(a, b)
 	}
 	return result
 }
 
+// Insert adds one or more integers to the set. It is the Set adapter for Add.
+func (set *BriggsSet[T]) Insert(ints ...T) {
+	set.Add(ints...)
+}
+
+// Delete removes one or more integers from the set. It is the Set adapter
+// for Remove.
+func (set *BriggsSet[T]) Delete(ints ...T) {
+	set.Remove(ints...)
+}
+
+// CloneSet is the Set adapter for Clone.
+func (set *BriggsSet[T]) CloneSet() Set[T] {
+	return set.Clone()
+}
+
+// UnionSet is the Set adapter for Union.
+func (set *BriggsSet[T]) UnionSet(other Set[T]) Set[T] {
+	return set.Union(other)
+}
+
+// IntersectionSet is the Set adapter for Intersection.
+func (set *BriggsSet[T]) IntersectionSet(other Set[T]) Set[T] {
+	return set.Intersection(other)
+}
+
+// DifferenceSet is the Set adapter for Difference.
+func (set *BriggsSet[T]) DifferenceSet(other Set[T]) Set[T] {
+	return set.Difference(other)
+}
+
+// SymetricDifferenceSet is the Set adapter for SymetricDifference.
+func (set *BriggsSet[T]) SymetricDifferenceSet(other Set[T]) Set[T] {
+	return set.SymetricDifference(other)
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (set *BriggsSet[T]) MarshalBinary() ([]byte, error) {
+	return marshalBinary(tagBriggsSet, set.All()), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface. The
+// data may have been marshalled from any set type.
+func (set *BriggsSet[T]) UnmarshalBinary(data []byte) error {
+	ints, err := unmarshalBinary[T](data)
+	if err != nil {
+		return err
+	}
+	return set.load(ints)
+}
+
+// MarshalJSON implements the json.Marshaler interface. The set is marshalled
+// as an array of integers in ascending order.
+func (set *BriggsSet[T]) MarshalJSON() ([]byte, error) {
+	return marshalJSON(set.All()), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It accepts an array
+// of integers or a string in the form produced by MarshalText.
+func (set *BriggsSet[T]) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, set.load, expanded(set.load))
+}
+
+// MarshalText implements the encoding.TextMarshaler interface. The set is
+// marshalled as a comma separated list of integers and ranges of integers in
+// ascending order, e.g. "1-5,9".
+func (set *BriggsSet[T]) MarshalText() ([]byte, error) {
+	return marshalText(set.All()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface. It accepts
+// the form produced by MarshalText as well as the one produced by String.
+func (set *BriggsSet[T]) UnmarshalText(text []byte) error {
+	return unmarshalText(text, expanded(set.load))
+}
+
+// load replaces the contents of the set with the given integers. The set keeps
+// its range if it is large enough for the integers, otherwise it is enlarged.
+// It returns an error if the integers are too far apart for the set to hold.
+func (set *BriggsSet[T]) load(ints []T) error {
+	if err := checkInts("BriggsSet", ints); err != nil {
+		return err
+	}
+	for _, v := range ints {
+		if !set.limit.allows(int(v)) {
+			return fmt.Errorf("intset: %v is out of range for BriggsSet", v)
+		}
+	}
+	lo, hi, err := denseSpan("BriggsSet", set.base, set.base+len(set.sparse)-1, ints)
+	if err != nil {
+		return err
+	}
+	set.init(lo, hi)
+	set.Add(ints...)
+	return nil
+}
+
 // String implements the Stringer interface for BriggsSet.
-func (set *BriggsSet) String() string {
-	items := make([]string, 0, len(set.dense))
+func (set *BriggsSet[T]) String() string {
+	items := make([]string, 0, set.size)
 
-	for _, i := range set.dense {
+	for _, i := range set.dense[:set.size] {
 		items = append(items, fmt.Sprintf("%v", i))
 	}
 	return fmt.Sprintf("Set{%s}", strings.Join(items, ", "))
@@ -1,6 +1,9 @@
 package intset
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"math/rand"
 	"sort"
 	"testing"
@@ -26,6 +29,142 @@ func TestBriggsSetRemove(t *testing.T) {
 	specs.Expect(set.Contains(1, 3), false)
 }
 
+func TestBriggsSetRanges(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewBriggsSet(200).AddRange(10, 140)
+	specs.Expect(set.Size(), 131)
+	specs.Expect(set.Contains(10, 63, 64, 140), true)
+	specs.Expect(set.Contains(9), false)
+	specs.Expect(set.Contains(141), false)
+
+	set.RemoveRange(20, 129)
+	specs.Expect(set.Size(), 21)
+	specs.Expect(set.Contains(19, 130), true)
+	specs.Expect(set.Contains(20), false)
+	specs.Expect(set.Contains(129), false)
+
+	set.FlipRange(15, 25)
+	specs.Expect(set.Size(), 22)
+	specs.Expect(set.Contains(14, 20, 25), true)
+	specs.Expect(set.Contains(15), false)
+	specs.Expect(set.Contains(26), false)
+
+	set.RemoveRange(-5, 1000)
+	specs.Expect(set.Size(), 0)
+	set.AddRange(5, 4)
+	specs.Expect(set.Size(), 0)
+}
+
+func TestBriggsSetAddRangeOutOfRange(t *testing.T) {
+	specs := specs.New(t)
+
+	defer func() {
+		specs.Expect(recover() != nil, true)
+	}()
+	NewBriggsSet(200).Limit(0, 200).AddRange(100, 201)
+}
+
+func TestBriggsSetRange(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewBriggsSetRange(-10, 10).Add(-10, -3, 10)
+	specs.Expect(set.Contains(-10, -3, 10), true)
+	specs.Expect(set.Contains(-11), false)
+	set.RemoveRange(-20, -5)
+	specs.Expect(set.Size(), 2)
+
+	set.UnionWith(NewBriggsSetRange(-50, 0).Add(-50))
+	specs.Expect(set.Contains(-50, -3, 10), true)
+	specs.Expect(set.Size(), 3)
+
+	specs.Expect(set.UnmarshalText([]byte("-100,20")), nil)
+	specs.Expect(set.Contains(-100, 20), true)
+	specs.Expect(set.Size(), 2)
+
+	defer func() {
+		specs.Expect(recover() != nil, true)
+	}()
+	NewBriggsSetRange(-10, 10).Limit(-10, 10).Add(-11)
+}
+
+func TestBriggsSetGrow(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewBriggsSet(10).Add(1, 1000, -5)
+	specs.Expect(set.Contains(1, 1000, -5), true)
+	specs.Expect(set.Contains(2000, -2000), false)
+	specs.Expect(set.Size(), 3)
+	set.AddRange(-20, -10).FlipRange(990, 1010)
+	specs.Expect(set.Size(), 33)
+	specs.Expect(set.Contains(-20, 990, 1010), true)
+	specs.Expect(set.Contains(1000), false)
+
+	var zero BriggsSet[int]
+	specs.Expect(zero.Contains(3), false)
+	specs.Expect(zero.Add(3).Contains(3), true)
+
+	limited := NewBriggsSet(10).Add(5).Limit(0, 100).Add(100)
+	specs.Expect(limited.Contains(5, 100), true)
+	specs.Expect(limited.UnmarshalText([]byte("101")) != nil, true)
+	specs.Expect(limited.Contains(5, 100), true)
+
+	defer func() {
+		specs.Expect(recover() != nil, true)
+	}()
+	limited.UnionWith(NewBriggsSet(0).Add(101))
+}
+
+func TestBriggsSetGrowBounds(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewBriggsSetRange(math.MaxInt-10, math.MaxInt-5).Add(math.MaxInt-5, math.MaxInt)
+	specs.Expect(set.Contains(math.MaxInt-5, math.MaxInt), true)
+	specs.Expect(set.Size(), 2)
+
+	set = NewBriggsSetRange(math.MinInt+5, math.MinInt+10).Add(math.MinInt+5, math.MinInt)
+	specs.Expect(set.Contains(math.MinInt+5, math.MinInt), true)
+	specs.Expect(set.Size(), 2)
+	set.AddRange(math.MinInt, math.MinInt+20)
+	specs.Expect(set.Size(), 21)
+
+	defer func() {
+		specs.Expect(fmt.Sprint(recover()), "intset: BriggsSet cannot grow to hold the integers from 0 to 9223372036854775807")
+	}()
+	NewBriggsSet(0).Add(math.MaxInt)
+}
+
+func TestBriggsSetTry(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewBriggsSetRange(-10, 10).Limit(-10, 10)
+	specs.Expect(set.TryAdd(-10, 3, 10), nil)
+	specs.Expect(set.Contains(-10, 3, 10), true)
+
+	err := set.TryAdd(4, 11)
+	specs.Expect(errors.Is(err, ErrOutOfRange), true)
+	var rangeErr *OutOfRangeError[int]
+	specs.Expect(errors.As(err, &rangeErr), true)
+	specs.Expect(*rangeErr, OutOfRangeError[int]{Value: 11, Min: -10, Max: 10})
+	specs.Expect(err.Error(), "intset: 11 is out of range [-10, 10]")
+	specs.Expect(set.Contains(4), false)
+
+	specs.Expect(errors.Is(set.TryRemove(3, -11), ErrOutOfRange), true)
+	specs.Expect(set.Contains(3), true)
+	specs.Expect(set.TryRemove(3), nil)
+	specs.Expect(set.Contains(3), false)
+
+	ok, err := set.TryContains(-10, 10)
+	specs.Expect(ok, true)
+	specs.Expect(err, nil)
+	ok, err = set.TryContains(20)
+	specs.Expect(ok, false)
+	specs.Expect(errors.Is(err, ErrOutOfRange), true)
+
+	specs.Expect(NewBriggsSet(10).TryAdd(1000), nil)
+	specs.Expect(errors.Is(NewBriggsSetOf[uint64](10).TryAdd(1<<64-1), ErrOutOfRange), true)
+}
+
 func TestBriggsSetContains(t *testing.T) {
 	specs := specs.New(t)
 
@@ -67,6 +206,25 @@ func TestBriggsSetAll(t *testing.T) {
 	specs.Expect(all[2], 99)
 }
 
+func TestBriggsSetValues(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewBriggsSet(100).Add(99, 1, 5)
+	var all []int
+	for i := range set.Values() {
+		all = append(all, i)
+	}
+	sort.Ints(all) // BriggsSet.Values() doesn't guarantee order of ints
+	specs.Expect(all, []int{1, 5, 99})
+
+	all = nil
+	for i := range set.Values() {
+		all = append(all, i)
+		break
+	}
+	specs.Expect(len(all), 1)
+}
+
 func TestBriggsSetEqual(t *testing.T) {
 	specs := specs.New(t)
 
@@ -142,6 +300,28 @@ func TestBriggsSetClone(t *testing.T) {
 	specs.Expect(setB.Equal(setA), true)
 }
 
+func TestBriggsSetInPlace(t *testing.T) {
+	specs := specs.New(t)
+
+	a := NewBriggsSet(20).Add(1, 2, 4, 10)
+	for _, b := range []IntSet{NewBriggsSet(20).Add(2, 3, 10, 15), NewHashSet(20).Add(2, 3, 10, 15)} {
+		specs.Expect(a.Clone().UnionWith(b).Equal(a.Union(b)), true)
+		specs.Expect(a.Clone().IntersectWith(b).Equal(a.Intersection(b)), true)
+		specs.Expect(a.Clone().DifferenceWith(b).Equal(a.Difference(b)), true)
+		specs.Expect(a.Clone().SymmetricDifferenceWith(b).Equal(a.SymetricDifference(b)), true)
+	}
+
+	c := a.Clone()
+	specs.Expect(c.UnionWith(c).Equal(a), true)
+	specs.Expect(c.IntersectWith(c).Equal(a), true)
+	specs.Expect(c.Clone().DifferenceWith(c).Size(), 0)
+	specs.Expect(c.SymmetricDifferenceWith(c).Size(), 0)
+
+	// the range of the set is enlarged to hold the other set
+	specs.Expect(NewBriggsSet(5).Add(1).UnionWith(NewHashSet(0).Add(40)).Contains(1, 40), true)
+	specs.Expect(NewBriggsSet(5).Add(1).SymmetricDifferenceWith(NewHashSet(0).Add(40)).Contains(1, 40), true)
+}
+
 // Benchmarks
 
 func BenchmarkBriggsSetAdd(b *testing.B) {
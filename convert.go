@@ -0,0 +1,214 @@
+package intset
+
+import "fmt"
+
+// bounds returns the smallest and the largest integer in set as ints. The last
+// return value is false if the set is empty. It panics if they do not fit in
+// an int, or if they are too far apart for a dense set to hold, naming the set
+// type name in the message.
+func bounds[T Integer](set Set[T], name string) (int, int, bool) {
+	first, ok := set.Min()
+	if !ok {
+		return 0, 0, false
+	}
+	last, _ := set.Max()
+	lo, hi := checkLimit(limit{}, name, first), checkLimit(limit{}, name, last)
+	if uint(hi)-uint(lo) >= maxDense {
+		panic(fmt.Sprintf("intset: %s cannot hold the integers from %d to %d", name, lo, hi))
+	}
+	return lo, hi, true
+}
+
+// viewOf returns the result of converting the set wrapped by s with to, while
+// holding the read lock of s, so that the bounds and the integers which to
+// reads from the set are consistent.
+func viewOf[S any, T Integer](s *SyncSet[T], to func(Set[T]) S) S {
+	var result S
+	s.View(func(set Set[T]) {
+		result = to(set)
+	})
+	return result
+}
+
+// ToArraySet returns a new ArraySet with the integers in set. A SyncSet is read
+// under its read lock.
+func ToArraySet[T Integer](set Set[T]) *ArraySet[T] {
+	switch s := set.(type) {
+	case *ArraySet[T]:
+		return s.Clone()
+	case *SyncSet[T]:
+		return viewOf(s, ToArraySet[T])
+	}
+	return &ArraySet[T]{data: sortedOf(set)}
+}
+
+// ToBitSet returns a new BitSet with the integers in set. Its words are
+// allocated once, to span exactly the integers of set. A SyncSet is read
+// under its read lock. It panics if an integer does not fit in an int.
+func ToBitSet[T Integer](set Set[T]) *BitSet[T] {
+	switch s := set.(type) {
+	case *BitSet[T]:
+		return s.Clone()
+	case *SyncSet[T]:
+		return viewOf(s, ToBitSet[T])
+	}
+	result := NewBitSetOf[T](0)
+	lo, hi, ok := bounds(set, "BitSet")
+	if !ok {
+		return result
+	}
+	result.grow(lo>>6, hi>>6)
+	if s, ok := set.(*IntervalSet[T]); ok {
+		for _, r := range s.runs {
+			result.AddRange(r.Lo, r.Hi)
+		}
+		return result
+	}
+	for v := range set.Values() {
+		result.Add(v)
+	}
+	return result
+}
+
+// ToBriggsSet returns a new BriggsSet with the integers in set. Its storage is
+// allocated once, to hold the integers from the smallest to the largest
+// integer of set. A SyncSet is read under its read lock. It panics if an
+// integer does not fit in an int.
+func ToBriggsSet[T Integer](set Set[T]) *BriggsSet[T] {
+	switch s := set.(type) {
+	case *BriggsSet[T]:
+		return s.Clone()
+	case *SyncSet[T]:
+		return viewOf(s, ToBriggsSet[T])
+	}
+	lo, hi, ok := bounds(set, "BriggsSet")
+	if !ok {
+		return NewBriggsSetRangeOf[T](0, -1)
+	}
+	result := NewBriggsSetRangeOf[T](lo, hi)
+	for v := range set.Values() {
+		result.Add(v)
+	}
+	return result
+}
+
+// ToHashSet returns a new HashSet with the integers in set. Its map is
+// allocated once, with room for all of them. A SyncSet is read under its read
+// lock.
+func ToHashSet[T Integer](set Set[T]) *HashSet[T] {
+	switch s := set.(type) {
+	case *HashSet[T]:
+		return s.Clone()
+	case *SyncSet[T]:
+		return viewOf(s, ToHashSet[T])
+	}
+	result := &HashSet[T]{data: make(map[T]bool, set.Size())}
+	for v := range set.Values() {
+		result.data[v] = true
+	}
+	return result
+}
+
+// ToIntervalSet returns a new IntervalSet with the integers in set. A SyncSet
+// is read under its read lock.
+func ToIntervalSet[T Integer](set Set[T]) *IntervalSet[T] {
+	switch s := set.(type) {
+	case *IntervalSet[T]:
+		return s.Clone()
+	case *SyncSet[T]:
+		return viewOf(s, ToIntervalSet[T])
+	}
+	return new(IntervalSet[T]).setRuns(runsOf(set))
+}
+
+// ToRoaringSet returns a new RoaringSet with the integers in set. A SyncSet is
+// read under its read lock. It panics if an integer is negative or does not
+// fit in 32 bits.
+func ToRoaringSet(set IntSet) *RoaringSet {
+	switch s := set.(type) {
+	case *RoaringSet:
+		return s.Clone()
+	case *SyncSet[int]:
+		return viewOf(s, ToRoaringSet)
+	}
+	return NewRoaringSet(0).UnionWith(set)
+}
+
+// ToSliceSet returns a new SliceSet with the integers in set. Its slice is
+// allocated once, to hold the integers from the smallest to the largest
+// integer of set. A SyncSet is read under its read lock. It panics if an
+// integer does not fit in an int.
+func ToSliceSet[T Integer](set Set[T]) *SliceSet[T] {
+	switch s := set.(type) {
+	case *SliceSet[T]:
+		return s.Clone()
+	case *SyncSet[T]:
+		return viewOf(s, ToSliceSet[T])
+	}
+	lo, hi, ok := bounds(set, "SliceSet")
+	if !ok {
+		return NewSliceSetRangeOf[T](0, -1)
+	}
+	result := NewSliceSetRangeOf[T](lo, hi)
+	for v := range set.Values() {
+		result.Add(v)
+	}
+	return result
+}
+
+// ToAdaptiveSet returns a new AdaptiveSet with the integers in set, with the
+// default thresholds. A set of one of the representations of AdaptiveSet is
+// cloned into that representation before the best one is chosen.
+func ToAdaptiveSet[T Integer](set Set[T]) *AdaptiveSet[T] {
+	if s, ok := set.(*AdaptiveSet[T]); ok {
+		return s.Clone()
+	}
+	result := &AdaptiveSet[T]{thresholds: DefaultAdaptiveThresholds}
+	switch set.(type) {
+	case *ArraySet[T], *BitSet[T], *HashSet[T], *IntervalSet[T]:
+		return result.derive(set.CloneSet())
+	}
+	return result.derive(ToArraySet(set))
+}
+
+// From returns a new set of type S with the integers in set, using the
+// conversion function for S. For example, From[*BitSet[int]](set) is
+// ToBitSet(set). A SyncSet is given a clone of set, or of the set wrapped by
+// set if it is a SyncSet itself. If S is an interface, the
+// result is a clone of set. It panics if the set cannot be converted to S.
+func From[S Set[T], T Integer](set Set[T]) S {
+	var result any
+	switch any(*new(S)).(type) {
+	case *AdaptiveSet[T]:
+		result = ToAdaptiveSet(set)
+	case *ArraySet[T]:
+		result = ToArraySet(set)
+	case *BitSet[T]:
+		result = ToBitSet(set)
+	case *BriggsSet[T]:
+		result = ToBriggsSet(set)
+	case *HashSet[T]:
+		result = ToHashSet(set)
+	case *IntervalSet[T]:
+		result = ToIntervalSet(set)
+	case *RoaringSet:
+		result = ToRoaringSet(any(set).(IntSet))
+	case *SliceSet[T]:
+		result = ToSliceSet(set)
+	case *SyncSet[T]:
+		if s, ok := set.(*SyncSet[T]); ok {
+			result = Synchronized(viewOf(s, func(set Set[T]) Set[T] {
+				return set.CloneSet()
+			}))
+		} else {
+			result = Synchronized(set.CloneSet())
+		}
+	default:
+		result = set.CloneSet()
+	}
+	s, ok := result.(S)
+	if !ok {
+		panic(fmt.Sprintf("intset: cannot convert %T to %T", set, *new(S)))
+	}
+	return s
+}
@@ -0,0 +1,200 @@
+package intset
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/knakk/specs"
+)
+
+func TestConvert(t *testing.T) {
+	specs := specs.New(t)
+
+	ints := []int{3, 64, 65, 66, 67, 1000}
+	sources := []IntSet{
+		NewHashSet(0).Add(ints...),
+		NewIntervalSet(0).Add(ints...),
+		Synchronized[int](NewBitSet(0).Add(ints...)),
+	}
+	for _, f := range newSets {
+		sources = append(sources, newIntSet(f, 1000, ints...))
+	}
+
+	for _, src := range sources {
+		results := []IntSet{
+			ToAdaptiveSet(src),
+			ToArraySet(src),
+			ToBitSet(src),
+			ToBriggsSet(src),
+			ToHashSet(src),
+			ToIntervalSet(src),
+			ToRoaringSet(src),
+			ToSliceSet(src),
+		}
+		for _, result := range results {
+			specs.Expect(result.Equal(src), true)
+			specs.Expect(result.Size(), len(ints))
+
+			// the result does not share storage with the source
+			result.Insert(500)
+			specs.Expect(src.Contains(500), false)
+		}
+	}
+}
+
+func TestConvertSizing(t *testing.T) {
+	specs := specs.New(t)
+
+	src := NewHashSet(0).Add(-70, 3, 64, 1000)
+
+	slice := ToSliceSet(src)
+	specs.Expect(slice.base, -70)
+	specs.Expect(len(slice.data), 1071)
+	specs.Expect(slice.Size(), 4)
+
+	briggs := ToBriggsSet(src)
+	specs.Expect(briggs.base, -70)
+	specs.Expect(len(briggs.sparse), 1071)
+	specs.Expect(briggs.Equal(src), true)
+
+	bits := ToBitSet(src)
+	specs.Expect(bits.base, -2)
+	specs.Expect(len(bits.words), 18)
+	specs.Expect(bits.All(), []int{-70, 3, 64, 1000})
+
+	empty := NewHashSet(0)
+	specs.Expect(ToSliceSet(empty).Add(5).All(), []int{5})
+	specs.Expect(ToBriggsSet(empty).Add(5).All(), []int{5})
+	specs.Expect(ToBitSet(empty).Size(), 0)
+
+	runs := ToBitSet(NewIntervalSet(0).AddRange(-100, 100).AddRange(500, 700))
+	specs.Expect(runs.Size(), 402)
+	specs.Expect(runs.Contains(-100, 100, 500, 700), true)
+	specs.Expect(runs.Contains(101, 499), false)
+}
+
+func TestConvertOutOfRange(t *testing.T) {
+	specs := specs.New(t)
+
+	defer func() {
+		specs.Expect(recover() != nil, true)
+	}()
+	ToSliceSet[uint64](NewHashSetOf[uint64](0).Add(1 << 63))
+}
+
+func TestConvertFarApart(t *testing.T) {
+	specs := specs.New(t)
+
+	wide := NewHashSet(0).Add(math.MinInt, math.MaxInt)
+	for _, c := range []struct {
+		name    string
+		convert func(IntSet)
+	}{
+		{"BitSet", func(set IntSet) { ToBitSet(set) }},
+		{"BriggsSet", func(set IntSet) { ToBriggsSet(set) }},
+		{"SliceSet", func(set IntSet) { ToSliceSet(set) }},
+	} {
+		func() {
+			defer func() {
+				specs.Expect(fmt.Sprint(recover()), "intset: "+c.name+" cannot hold the integers from -9223372036854775808 to 9223372036854775807")
+			}()
+			c.convert(wide)
+		}()
+	}
+	specs.Expect(ToIntervalSet[int](wide).Runs(), []Interval[int]{{math.MinInt, math.MinInt}, {math.MaxInt, math.MaxInt}})
+}
+
+func TestConvertSyncSet(t *testing.T) {
+	specs := specs.New(t)
+
+	// the writer slides a window of 64 integers upwards, so that the integers
+	// keep moving past the bounds of the set
+	set := Synchronized[int](NewHashSet(0).AddRange(0, 63))
+	done := make(chan bool)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 64; ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			set.Add(i)
+			set.Remove(i - 64)
+		}
+	}()
+	for i := 0; i < 10000; i++ {
+		for _, result := range []IntSet{
+			ToArraySet(set), ToBitSet(set), ToBriggsSet(set),
+			ToHashSet(set), ToIntervalSet(set), ToSliceSet(set),
+		} {
+			lo, _ := result.Min()
+			hi, _ := result.Max()
+			specs.Expect(result.Size() >= 63 && hi-lo <= 64, true)
+		}
+	}
+	close(done)
+	wg.Wait()
+	specs.Expect(ToSliceSet(set).Equal(set), true)
+	specs.Expect(ToBriggsSet(set).Equal(set), true)
+	specs.Expect(ToBitSet(set).Equal(set), true)
+}
+
+func TestFrom(t *testing.T) {
+	specs := specs.New(t)
+
+	src := NewHashSet(0).Add(1, 5, 9)
+
+	bits := From[*BitSet[int]](src)
+	specs.Expect(bits.All(), []int{1, 5, 9})
+	specs.Expect(From[*SliceSet[int]](src).All(), []int{1, 5, 9})
+	specs.Expect(From[*RoaringSet](src).All(), []int{1, 5, 9})
+	specs.Expect(From[*AdaptiveSet[int]](bits).Representation(), ArrayRepresentation)
+	specs.Expect(From[*SyncSet[int]](bits).Equal(src), true)
+
+	// a SyncSet is not wrapped twice
+	nested := From[*SyncSet[int]](Synchronized[int](bits))
+	nested.View(func(set IntSet) {
+		_, ok := set.(*BitSet[int])
+		specs.Expect(ok, true)
+	})
+	specs.Expect(nested.Equal(src), true)
+
+	clone := From[IntSet](src)
+	clone.Insert(2)
+	specs.Expect(clone.Size(), 4)
+	specs.Expect(src.Size(), 3)
+
+	bytes := From[*IntervalSet[uint8]](NewHashSetOf[uint8](0).Add(1, 2, 3, 255))
+	specs.Expect(bytes.Runs(), []Interval[uint8]{{1, 3}, {255, 255}})
+}
+
+// Benchmarks
+
+func BenchmarkConvertHashSetToBitSet(b *testing.B) {
+	set := NewHashSet(0)
+	for i := 0; i < 10000; i++ {
+		set.Add(i * 3)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = ToBitSet(set)
+	}
+}
+
+func BenchmarkConvertHashSetToSliceSet(b *testing.B) {
+	set := NewHashSet(0)
+	for i := 0; i < 10000; i++ {
+		set.Add(i * 3)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = ToSliceSet(set)
+	}
+}
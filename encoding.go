@@ -0,0 +1,371 @@
+package intset
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// All the set types share the same binary format, so a set marshalled from
+// one type can be unmarshalled into any other type able to hold its integers.
+// Fixed size fields are little-endian.
+//
+//	magic     4 bytes  "ISET"
+//	version   1 byte   binaryVersion
+//	type      1 byte   the type of the marshalled set, one of the tag constants
+//	flags     1 byte   bit 0 is set if the integers are of a signed type
+//	reserved  1 byte   always 0
+//	count     8 bytes  the number of integers
+//	integers           the integers in ascending order; the first one as a
+//	                   varint (uvarint if unsigned), the following ones as the
+//	                   uvarint difference from the previous integer
+//	checksum  4 bytes  CRC-32 (IEEE) of all the preceding bytes
+const (
+	binaryMagic   = "ISET"
+	binaryVersion = 1
+
+	binaryHeaderSize = 16
+	binaryCRCSize    = 4
+
+	flagSigned = 1 << 0
+)
+
+// Type tags of the binary format.
+const (
+	tagBitSet byte = iota + 1
+	tagBriggsSet
+	tagHashSet
+	tagRoaringSet
+	tagSliceSet
+	tagArraySet
+	tagIntervalSet
+	tagAdaptiveSet
+)
+
+// ErrChecksum is returned by UnmarshalBinary when the checksum of the data
+// does not match its contents.
+var ErrChecksum = errors.New("intset: binary data checksum mismatch")
+
+// signed returns true if T is a signed integer type.
+func signed[T Integer]() bool {
+	var zero T
+	return zero-1 < zero
+}
+
+// marshalBinary encodes the integers in the binary format. The integers are
+// sorted in place.
+func marshalBinary[T Integer](tag byte, ints []T) []byte {
+	slices.Sort(ints)
+
+	var flags byte
+	if signed[T]() {
+		flags |= flagSigned
+	}
+	buf := make([]byte, 0, binaryHeaderSize+2*len(ints)+binaryCRCSize)
+	buf = append(buf, binaryMagic...)
+	buf = append(buf, binaryVersion, tag, flags, 0)
+	buf = binary.LittleEndian.AppendUint64(buf, uint64(len(ints)))
+	for k, v := range ints {
+		switch {
+		case k > 0:
+			buf = binary.AppendUvarint(buf, uint64(v)-uint64(ints[k-1]))
+		case flags&flagSigned != 0:
+			buf = binary.AppendVarint(buf, int64(v))
+		default:
+			buf = binary.AppendUvarint(buf, uint64(v))
+		}
+	}
+	return binary.LittleEndian.AppendUint32(buf, crc32.ChecksumIEEE(buf))
+}
+
+// unmarshalBinary decodes integers in the binary format, in ascending order.
+// It returns an error if the data is malformed or if an integer cannot be
+// represented by T.
+func unmarshalBinary[T Integer](data []byte) ([]T, error) {
+	if len(data) < binaryHeaderSize+binaryCRCSize || string(data[:4]) != binaryMagic {
+		return nil, errors.New("intset: invalid binary data")
+	}
+	body := data[:len(data)-binaryCRCSize]
+	if crc32.ChecksumIEEE(body) != binary.LittleEndian.Uint32(data[len(body):]) {
+		return nil, ErrChecksum
+	}
+	if v := data[4]; v != binaryVersion {
+		return nil, fmt.Errorf("intset: unsupported binary format version %d", v)
+	}
+	isSigned := data[6]&flagSigned != 0
+	n := binary.LittleEndian.Uint64(data[8:16])
+	buf := body[binaryHeaderSize:]
+	if n > uint64(len(buf)) {
+		return nil, errors.New("intset: invalid binary data: count exceeds data size")
+	}
+
+	ints := make([]T, 0, n)
+	var x uint64
+	for k := uint64(0); k < n; k++ {
+		var d uint64
+		var m int
+		if k == 0 && isSigned {
+			var i int64
+			i, m = binary.Varint(buf)
+			d = uint64(i)
+		} else {
+			d, m = binary.Uvarint(buf)
+		}
+		if m <= 0 {
+			return nil, errors.New("intset: invalid binary data: malformed integer")
+		}
+		buf = buf[m:]
+
+		prev := x
+		if k == 0 {
+			x = d
+		} else {
+			x += d
+			if d == 0 || (isSigned && int64(x) < int64(prev)) || (!isSigned && x < prev) {
+				return nil, errors.New("intset: invalid binary data: integers not in ascending order")
+			}
+		}
+
+		v, err := convert[T](x, isSigned)
+		if err != nil {
+			return nil, err
+		}
+		ints = append(ints, v)
+	}
+	if len(buf) != 0 {
+		return nil, errors.New("intset: invalid binary data: trailing bytes")
+	}
+	return ints, nil
+}
+
+// convert converts x to T, where x holds an int64 if isSigned is true and an
+// uint64 otherwise. It returns an error if the integer cannot be represented
+// by T.
+func convert[T Integer](x uint64, isSigned bool) (T, error) {
+	v := T(x)
+	if isSigned && (int64(v) != int64(x) || (v < 0) != (int64(x) < 0)) {
+		return v, fmt.Errorf("intset: %d is out of range for %T", int64(x), v)
+	}
+	if !isSigned && (uint64(v) != x || v < 0) {
+		return v, fmt.Errorf("intset: %d is out of range for %T", x, v)
+	}
+	return v, nil
+}
+
+// checkInts returns an error if any of the integers does not fit in an int.
+func checkInts[T Integer](name string, ints []T) error {
+	for _, v := range ints {
+		if _, ok := toInt(v); !ok {
+			return fmt.Errorf("intset: %v is out of range for %s", v, name)
+		}
+	}
+	return nil
+}
+
+// denseSpan returns the range which the storage of a dense set, holding the
+// integers from lo to hi, is set to in order to load ints: the range of ints,
+// widened to the current one unless that would make it hold more than
+// maxDense integers. It returns an error naming the set type name if ints
+// alone span more than maxDense integers. The ints must fit in an int.
+func denseSpan[T Integer](name string, lo, hi int, ints []T) (int, int, error) {
+	if len(ints) == 0 {
+		return lo, hi, nil
+	}
+	first, last := int(ints[0]), int(ints[0])
+	for _, v := range ints {
+		first, last = min(first, int(v)), max(last, int(v))
+	}
+	if uint(last)-uint(first) >= maxDense {
+		return 0, 0, fmt.Errorf("intset: %s cannot hold the integers from %d to %d", name, first, last)
+	}
+	if lo <= hi && uint(max(hi, last))-uint(min(lo, first)) < maxDense {
+		return min(lo, first), max(hi, last), nil
+	}
+	return first, last, nil
+}
+
+func appendInt[T Integer](buf []byte, v T) []byte {
+	if signed[T]() {
+		return strconv.AppendInt(buf, int64(v), 10)
+	}
+	return strconv.AppendUint(buf, uint64(v), 10)
+}
+
+func parseInt[T Integer](s string) (T, error) {
+	if strings.HasPrefix(s, "-") {
+		i, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("intset: invalid integer %q", s)
+		}
+		return convert[T](uint64(i), true)
+	}
+	u, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("intset: invalid integer %q", s)
+	}
+	return convert[T](u, false)
+}
+
+// marshalJSON encodes the integers as a JSON array in ascending order. The
+// integers are sorted in place.
+func marshalJSON[T Integer](ints []T) []byte {
+	slices.Sort(ints)
+
+	buf := append(make([]byte, 0, 2+4*len(ints)), '[')
+	for k, v := range ints {
+		if k > 0 {
+			buf = append(buf, ',')
+		}
+		buf = appendInt(buf, v)
+	}
+	return append(buf, ']')
+}
+
+// unmarshalJSON decodes a JSON array of integers, or a string in the text
+// form, and passes the integers to load, or the ranges of the text form to
+// loadRuns. A JSON null is ignored.
+func unmarshalJSON[T Integer](data []byte, load func([]T) error, loadRuns func([]Interval[T]) error) error {
+	if string(data) == "null" {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var text string
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+		return unmarshalText([]byte(text), loadRuns)
+	}
+	var ints []T
+	if err := json.Unmarshal(data, &ints); err != nil {
+		return err
+	}
+	return load(ints)
+}
+
+// marshalText encodes the integers in ascending order, separated by commas.
+// Runs of three or more consecutive integers are written as ranges, e.g.
+// "1-5,9". The integers are sorted in place.
+func marshalText[T Integer](ints []T) []byte {
+	slices.Sort(ints)
+
+	var buf []byte
+	for k := 0; k < len(ints); k++ {
+		if k > 0 {
+			buf = append(buf, ',')
+		}
+		buf = appendInt(buf, ints[k])
+		j := k
+		for j+1 < len(ints) && ints[j+1]-ints[j] == 1 {
+			j++
+		}
+		switch j - k {
+		case 0:
+		case 1:
+			buf = appendInt(append(buf, ','), ints[j])
+		default:
+			buf = appendInt(append(buf, '-'), ints[j])
+		}
+		k = j
+	}
+	return buf
+}
+
+// unmarshalText decodes integers in the form produced by marshalText, or in
+// the form produced by the String methods, e.g. "Set{1, 2, 3}", and passes
+// them to loadRuns as ranges, without expanding them.
+func unmarshalText[T Integer](text []byte, loadRuns func([]Interval[T]) error) error {
+	s := strings.TrimSpace(string(text))
+	if strings.HasPrefix(s, "Set{") && strings.HasSuffix(s, "}") {
+		s = s[len("Set{") : len(s)-1]
+	}
+	if strings.TrimSpace(s) == "" {
+		return loadRuns(nil)
+	}
+
+	var runs []Interval[T]
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		// the first character may be the sign of the lower bound
+		j := strings.Index(field[min(1, len(field)):], "-") + 1
+		if j <= 0 {
+			v, err := parseInt[T](field)
+			if err != nil {
+				return err
+			}
+			runs = append(runs, Interval[T]{v, v})
+			continue
+		}
+		lo, err := parseInt[T](strings.TrimSpace(field[:j]))
+		if err != nil {
+			return err
+		}
+		hi, err := parseInt[T](strings.TrimSpace(field[j+1:]))
+		if err != nil {
+			return err
+		}
+		if hi < lo {
+			return fmt.Errorf("intset: invalid range %q", field)
+		}
+		runs = append(runs, Interval[T]{lo, hi})
+	}
+	return loadRuns(runs)
+}
+
+// maxTextInts is the largest number of integers the ranges of the text form
+// are expanded to, so that a short text such as "0-9223372036854775807"
+// cannot exhaust memory.
+const maxTextInts = 1 << 24
+
+// expanded returns a function which expands ranges to integers and passes
+// them to load. It returns an error if the ranges hold more than maxTextInts
+// integers. It is used by the set types which cannot load ranges directly.
+func expanded[T Integer](load func([]T) error) func([]Interval[T]) error {
+	return func(runs []Interval[T]) error {
+		var n uint64
+		for _, r := range runs {
+			// the width of a run covering all the integers of T wraps around
+			// when it is added to, so the room left is compared first
+			w := uint64(r.Hi) - uint64(r.Lo)
+			if w >= maxTextInts-n {
+				return fmt.Errorf("intset: text holds more than %d integers", maxTextInts)
+			}
+			n += w + 1
+		}
+		ints := make([]T, 0, n)
+		for _, r := range runs {
+			for v := r.Lo; ; v++ {
+				ints = append(ints, v)
+				if v == r.Hi {
+					break
+				}
+			}
+		}
+		return load(ints)
+	}
+}
+
+// Ranges wraps a set so that it is marshalled to JSON as a string in the
+// compact form produced by MarshalText, e.g. "1-5,9", instead of as an array.
+type Ranges[T Integer] struct {
+	Set[T]
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (r Ranges[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(marshalText(r.All())))
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface by calling
+// UnmarshalJSON on the wrapped set, which must not be nil.
+func (r *Ranges[T]) UnmarshalJSON(data []byte) error {
+	u, ok := r.Set.(json.Unmarshaler)
+	if !ok {
+		return fmt.Errorf("intset: cannot unmarshal JSON into %T", r.Set)
+	}
+	return u.UnmarshalJSON(data)
+}
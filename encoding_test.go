@@ -0,0 +1,277 @@
+package intset
+
+import (
+	"encoding"
+	"encoding/binary"
+	"encoding/json"
+	"hash/crc32"
+	"math"
+	"testing"
+
+	"github.com/knakk/specs"
+)
+
+func TestMarshalBinaryFormat(t *testing.T) {
+	specs := specs.New(t)
+
+	data, err := NewBitSet(0).Add(1, 2, 300).MarshalBinary()
+	specs.Expect(err, nil)
+	specs.Expect(data, []byte{
+		'I', 'S', 'E', 'T', 1, tagBitSet, flagSigned, 0,
+		3, 0, 0, 0, 0, 0, 0, 0,
+		2, 1, 0xaa, 0x02,
+		0x29, 0xa3, 0xca, 0xb4,
+	})
+
+	data, err = NewHashSetOf[uint8](0).Add(255, 0).MarshalBinary()
+	specs.Expect(err, nil)
+	specs.Expect(data[:binaryHeaderSize+3], []byte{
+		'I', 'S', 'E', 'T', 1, tagHashSet, 0, 0,
+		2, 0, 0, 0, 0, 0, 0, 0,
+		0, 0xff, 0x01,
+	})
+}
+
+func TestMarshalBinaryRoundTrip(t *testing.T) {
+	specs := specs.New(t)
+
+	for _, fa := range newSets {
+		for _, fb := range newSets {
+			setA := newIntSet(fa, 100000, 0, 1, 2, 63, 64, 65, 1000, 99999)
+			data, err := setA.(encoding.BinaryMarshaler).MarshalBinary()
+			specs.Expect(err, nil)
+
+			setB := fb(10)
+			specs.Expect(setB.(encoding.BinaryUnmarshaler).UnmarshalBinary(data), nil)
+			specs.Expect(setB.Equal(setA), true)
+		}
+	}
+}
+
+func TestUnmarshalBinaryZeroValue(t *testing.T) {
+	specs := specs.New(t)
+
+	data, _ := NewHashSet(0).Add(7, 3).MarshalBinary()
+
+	var bitSet BitSet[int]
+	var briggsSet BriggsSet[int]
+	var hashSet HashSet[int]
+	var roaringSet RoaringSet
+	var sliceSet SliceSet[int]
+	for _, set := range []IntSet{&bitSet, &briggsSet, &hashSet, &roaringSet, &sliceSet} {
+		specs.Expect(set.(encoding.BinaryUnmarshaler).UnmarshalBinary(data), nil)
+		specs.Expect(set.Contains(3, 7), true)
+		specs.Expect(set.Size(), 2)
+	}
+}
+
+func TestUnmarshalBinaryIntegerTypes(t *testing.T) {
+	specs := specs.New(t)
+
+	data, _ := NewHashSetOf[int8](0).Add(-128, -1, 0, 127).MarshalBinary()
+
+	set64 := NewHashSetOf[int64](0)
+	specs.Expect(set64.UnmarshalBinary(data), nil)
+	specs.Expect(set64.Contains(-128, -1, 0, 127), true)
+
+	specs.Expect(NewHashSetOf[uint64](0).UnmarshalBinary(data) != nil, true)
+	specs.Expect(NewRoaringSet(0).UnmarshalBinary(data) != nil, true)
+
+	bitSet := NewBitSet(0)
+	specs.Expect(bitSet.UnmarshalBinary(data), nil)
+	specs.Expect(bitSet.Contains(-128, -1, 0, 127), true)
+
+	data, _ = NewHashSetOf[uint64](0).Add(1<<64-1, 1).MarshalBinary()
+	specs.Expect(NewHashSetOf[int64](0).UnmarshalBinary(data) != nil, true)
+	specs.Expect(NewBitSetOf[uint64](0).UnmarshalBinary(data) != nil, true)
+
+	setU64 := NewHashSetOf[uint64](0)
+	specs.Expect(setU64.UnmarshalBinary(data), nil)
+	specs.Expect(setU64.Contains(1<<64-1, 1), true)
+}
+
+func TestUnmarshalBinaryInvalid(t *testing.T) {
+	specs := specs.New(t)
+
+	data, _ := NewSliceSet(10).Add(1, 5, 9).MarshalBinary()
+	set := NewHashSet(0)
+
+	corrupt := append([]byte(nil), data...)
+	corrupt[binaryHeaderSize] ^= 1
+	specs.Expect(set.UnmarshalBinary(corrupt), ErrChecksum)
+
+	specs.Expect(set.UnmarshalBinary(data[:10]) != nil, true)
+	specs.Expect(set.UnmarshalBinary(nil) != nil, true)
+
+	future := append([]byte(nil), data[:len(data)-binaryCRCSize]...)
+	future[4] = binaryVersion + 1
+	future = appendCRC(future)
+	specs.Expect(set.UnmarshalBinary(future) != nil, true)
+
+	unsorted := append([]byte(nil), data[:binaryHeaderSize]...)
+	unsorted = append(unsorted, 10, 0, 1)
+	unsorted = appendCRC(unsorted)
+	specs.Expect(set.UnmarshalBinary(unsorted) != nil, true)
+
+	specs.Expect(set.Size(), 0)
+}
+
+func TestMarshalJSON(t *testing.T) {
+	specs := specs.New(t)
+
+	for _, f := range newSets {
+		set := newIntSet(f, 100, 9, 3, 1, 2, 4, 5)
+		data, err := json.Marshal(set)
+		specs.Expect(err, nil)
+		specs.Expect(string(data), "[1,2,3,4,5,9]")
+
+		data, err = json.Marshal(Ranges[int]{set})
+		specs.Expect(err, nil)
+		specs.Expect(string(data), `"1-5,9"`)
+
+		data, err = json.Marshal(f(10))
+		specs.Expect(err, nil)
+		specs.Expect(string(data), "[]")
+	}
+
+	data, err := json.Marshal(NewHashSetOf[int8](0).Add(-128, 127, -1))
+	specs.Expect(err, nil)
+	specs.Expect(string(data), "[-128,-1,127]")
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	specs := specs.New(t)
+
+	for _, f := range newSets {
+		set := f(10)
+		specs.Expect(json.Unmarshal([]byte("[9, 3, 1, 3]"), set), nil)
+		specs.Expect(set.Equal(NewHashSet(0).Add(1, 3, 9)), true)
+
+		specs.Expect(json.Unmarshal([]byte(`"1-3,20"`), set), nil)
+		specs.Expect(set.Equal(NewHashSet(0).Add(1, 2, 3, 20)), true)
+
+		specs.Expect(json.Unmarshal([]byte("null"), set), nil)
+		specs.Expect(set.Size(), 4)
+
+		specs.Expect(json.Unmarshal([]byte(`[1, "a"]`), set) != nil, true)
+		specs.Expect(json.Unmarshal([]byte(`"1-"`), set) != nil, true)
+		specs.Expect(set.Size(), 4)
+	}
+
+	var v struct {
+		IDs Ranges[int]
+	}
+	v.IDs.Set = NewBitSet(0)
+	specs.Expect(json.Unmarshal([]byte(`{"IDs": "1,5-7"}`), &v), nil)
+	specs.Expect(v.IDs.Equal(NewHashSet(0).Add(1, 5, 6, 7)), true)
+
+	data, err := json.Marshal(v)
+	specs.Expect(err, nil)
+	specs.Expect(string(data), `{"IDs":"1,5-7"}`)
+
+	specs.Expect(json.Unmarshal([]byte("[300]"), NewHashSetOf[uint8](0)) != nil, true)
+}
+
+func TestMarshalText(t *testing.T) {
+	specs := specs.New(t)
+
+	for _, text := range []string{"", "1", "1,2", "1-3", "0,2-4,6,7,9-11"} {
+		for _, f := range newSets {
+			set := f(10)
+			specs.Expect(set.(encoding.TextUnmarshaler).UnmarshalText([]byte(text)), nil)
+			data, err := set.(encoding.TextMarshaler).MarshalText()
+			specs.Expect(err, nil)
+			specs.Expect(string(data), text)
+		}
+	}
+
+	set := NewHashSetOf[int8](0)
+	specs.Expect(set.UnmarshalText([]byte("-128--126, -1-1, 126-127")), nil)
+	specs.Expect(set.Size(), 8)
+	data, _ := set.MarshalText()
+	specs.Expect(string(data), "-128--126,-1-1,126,127")
+}
+
+func TestUnmarshalTextString(t *testing.T) {
+	specs := specs.New(t)
+
+	for _, fa := range newSets {
+		for _, fb := range newSets {
+			setA := newIntSet(fa, 100, 42, 7, 0, 99)
+			setB := fb(10)
+			specs.Expect(setB.(encoding.TextUnmarshaler).UnmarshalText([]byte(setA.String())), nil)
+			specs.Expect(setB.Equal(setA), true)
+
+			specs.Expect(setB.(encoding.TextUnmarshaler).UnmarshalText([]byte(fa(10).String())), nil)
+			specs.Expect(setB.Size(), 0)
+		}
+	}
+
+	set := NewSliceSet(10)
+	for _, text := range []string{"Set{1,}", "1,,2", "3-1", "x", "1-2-3", "Set{1"} {
+		specs.Expect(set.UnmarshalText([]byte(text)) != nil, true)
+	}
+}
+
+func TestUnmarshalTextRanges(t *testing.T) {
+	specs := specs.New(t)
+
+	text := []byte("-5,0-4611686018427387903")
+	for _, set := range []encoding.TextUnmarshaler{
+		NewArraySet(0), NewBitSet(0), NewBriggsSet(0), NewHashSet(0), NewSliceSet(0),
+	} {
+		specs.Expect(set.UnmarshalText(text) != nil, true)
+	}
+	specs.Expect(NewHashSetOf[int64](0).UnmarshalText([]byte("-9223372036854775808-9223372036854775807")) != nil, true)
+
+	// a run of all the integers after another one must not wrap the count
+	full := "1,-9223372036854775808-9223372036854775807"
+	specs.Expect(NewHashSet(0).UnmarshalText([]byte(full)) != nil, true)
+	specs.Expect(json.Unmarshal([]byte(`"`+full+`"`), NewHashSet(0)) != nil, true)
+	specs.Expect(NewHashSetOf[uint8](0).UnmarshalText([]byte("1,0-255")), nil)
+
+	runs := NewIntervalSet(0)
+	specs.Expect(runs.UnmarshalText(text), nil)
+	specs.Expect(runs.Runs(), []Interval[int]{{-5, -5}, {0, 1<<62 - 1}})
+	specs.Expect(runs.Size(), 1<<62+1)
+
+	adaptive := NewAdaptiveSet(0)
+	specs.Expect(json.Unmarshal([]byte(`"`+string(text)+`"`), adaptive), nil)
+	specs.Expect(adaptive.Representation(), RunsRepresentation)
+	specs.Expect(adaptive.Size(), 1<<62+1)
+
+	roaring := NewRoaringSet(0)
+	specs.Expect(roaring.UnmarshalText([]byte("0-4294967295")), nil)
+	specs.Expect(roaring.Size(), 1<<32)
+	specs.Expect(roaring.UnmarshalText([]byte("0-4294967296")) != nil, true)
+	specs.Expect(roaring.UnmarshalText(text) != nil, true)
+	specs.Expect(roaring.Size(), 1<<32)
+}
+
+func TestUnmarshalFarApart(t *testing.T) {
+	specs := specs.New(t)
+
+	data, err := NewHashSet(0).Add(math.MinInt, math.MaxInt).MarshalBinary()
+	specs.Expect(err, nil)
+	for _, set := range []interface {
+		IntSet
+		encoding.BinaryUnmarshaler
+		encoding.TextUnmarshaler
+		json.Unmarshaler
+	}{
+		NewBitSet(0).Add(1), NewBriggsSet(0).Add(1), NewSliceSet(0).Add(1),
+	} {
+		specs.Expect(set.UnmarshalJSON([]byte("[-9223372036854775808,9223372036854775807]")) != nil, true)
+		specs.Expect(set.UnmarshalText([]byte("0,9223372036854775807")) != nil, true)
+		specs.Expect(set.UnmarshalBinary(data) != nil, true)
+		specs.Expect(set.Equal(NewHashSet(0).Add(1)), true)
+
+		// the range of the set is dropped if it is too far from the integers
+		specs.Expect(set.UnmarshalText([]byte("1099511627776")), nil)
+		specs.Expect(set.Equal(NewHashSet(0).Add(1<<40)), true)
+	}
+}
+
+func appendCRC(data []byte) []byte {
+	return binary.LittleEndian.AppendUint32(data, crc32.ChecksumIEEE(data))
+}
@@ -2,37 +2,43 @@ package intset
 
 import (
 	"fmt"
+	"iter"
 	"strings"
 )
 
 // HashSet is an integer set backed by a map.
-type HashSet struct {
-	data map[int]bool
+type HashSet[T Integer] struct {
+	data map[T]bool
 }
 
-// NewHashSet is the constructor for HashSet.
-func NewHashSet(max int) *HashSet {
-	return new(HashSet).init(max)
+// NewHashSet is the constructor for a HashSet of ints.
+func NewHashSet(max int) *HashSet[int] {
+	return NewHashSetOf[int](max)
 }
 
-func (set *HashSet) init(max int) *HashSet {
-	set.data = make(map[int]bool)
+// NewHashSetOf is the constructor for a HashSet of any integer type.
+func NewHashSetOf[T Integer](max int) *HashSet[T] {
+	return new(HashSet[T]).init(max)
+}
+
+func (set *HashSet[T]) init(max int) *HashSet[T] {
+	set.data = make(map[T]bool)
 	return set
 }
 
 // Clear the set.
-func (set *HashSet) Clear() *HashSet {
-	set.data = make(map[int]bool, len(set.data))
+func (set *HashSet[T]) Clear() *HashSet[T] {
+	set.data = make(map[T]bool, len(set.data))
 	return set
 }
 
 // Size returns the number of integers in the set.
-func (set *HashSet) Size() int {
+func (set *HashSet[T]) Size() int {
 	return len(set.data)
 }
 
 // Add one or more integers to the set.
-func (set *HashSet) Add(ints ...int) *HashSet {
+func (set *HashSet[T]) Add(ints ...T) *HashSet[T] {
 	for _, i := range ints {
 		set.data[i] = true
 	}
@@ -40,25 +46,114 @@ func (set *HashSet) Add(ints ...int) *HashSet {
 }
 
 // Remove one or more integers from the set.
-func (set *HashSet) Remove(ints ...int) *HashSet {
+func (set *HashSet[T]) Remove(ints ...T) *HashSet[T] {
 	for _, i := range ints {
 		delete(set.data, i)
 	}
 	return set
 }
 
+// AddRange adds the integers from lo to hi, inclusive, to the set.
+func (set *HashSet[T]) AddRange(lo, hi T) *HashSet[T] {
+	if hi < lo {
+		return set
+	}
+	for i := lo; ; i++ {
+		set.data[i] = true
+		if i == hi {
+			return set
+		}
+	}
+}
+
+// RemoveRange removes the integers from lo to hi, inclusive, from the set.
+func (set *HashSet[T]) RemoveRange(lo, hi T) *HashSet[T] {
+	if hi < lo {
+		return set
+	}
+	if uint64(hi)-uint64(lo) >= uint64(len(set.data)) {
+		// the set is smaller than the range, so scan the set instead
+		for i := range set.data {
+			if i >= lo && i <= hi {
+				delete(set.data, i)
+			}
+		}
+		return set
+	}
+	for i := lo; ; i++ {
+		delete(set.data, i)
+		if i == hi {
+			return set
+		}
+	}
+}
+
+// FlipRange adds the integers from lo to hi, inclusive, which are not in the
+// set, and removes those which are.
+func (set *HashSet[T]) FlipRange(lo, hi T) *HashSet[T] {
+	if hi < lo {
+		return set
+	}
+	for i := lo; ; i++ {
+		if set.data[i] {
+			delete(set.data, i)
+		} else {
+			set.data[i] = true
+		}
+		if i == hi {
+			return set
+		}
+	}
+}
+
 // All returns a slice of all the integers in the set. It makes no guarantee
 // that the integers are in the same order as they where inserted.
-func (set *HashSet) All() []int {
-	var all []int
+func (set *HashSet[T]) All() []T {
+	var all []T
 	for i := range set.data {
 		all = append(all, i)
 	}
 	return all
 }
 
+// Values returns an iterator over the integers in the set. Like All, it
+// makes no guarantee about the order of the integers.
+func (set *HashSet[T]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := range set.data {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+// Min returns the smallest integer in the set. The second return value is
+// false if the set is empty.
+func (set *HashSet[T]) Min() (T, bool) {
+	return minOf(set.Values())
+}
+
+// Max returns the largest integer in the set. The second return value is false
+// if the set is empty.
+func (set *HashSet[T]) Max() (T, bool) {
+	return maxOf(set.Values())
+}
+
+// NextAfter returns the smallest integer in the set which is greater than v.
+// The second return value is false if there is no such integer.
+func (set *HashSet[T]) NextAfter(v T) (T, bool) {
+	return nextAfter(set.Values(), v)
+}
+
+// PrevBefore returns the largest integer in the set which is less than v. The
+// second return value is false if there is no such integer.
+func (set *HashSet[T]) PrevBefore(v T) (T, bool) {
+	return prevBefore(set.Values(), v)
+}
+
 // Contains returns true if all ints are in the set, otherwise false.
-func (set *HashSet) Contains(ints ...int) bool {
+func (set *HashSet[T]) Contains(ints ...T) bool {
 	for _, i := range ints {
 		if _, found := set.data[i]; !found {
 			return false
@@ -68,7 +163,7 @@ func (set *HashSet) Contains(ints ...int) bool {
 }
 
 // Equal checks if two sets both contains all the same items.
-func (set *HashSet) Equal(other *HashSet) bool {
+func (set *HashSet[T]) Equal(other Set[T]) bool {
 	if set.Size() != other.Size() {
 		return false
 	}
@@ -81,7 +176,7 @@ func (set *HashSet) Equal(other *HashSet) bool {
 }
 
 // SubsetOf checks if all items in set are also present in other set.
-func (set *HashSet) SubsetOf(other *HashSet) bool {
+func (set *HashSet[T]) SubsetOf(other Set[T]) bool {
 	for i := range set.data {
 		if !other.Contains(i) {
 			return false
@@ -91,27 +186,31 @@ func (set *HashSet) SubsetOf(other *HashSet) bool {
 }
 
 // SupersetOf checks if a set is a superset of another set.
-func (set *HashSet) SupersetOf(other *HashSet) bool {
+func (set *HashSet[T]) SupersetOf(other Set[T]) bool {
 	return other.SubsetOf(set)
 }
 
 // Union returns a new set which is the union of two sets.
-func (set *HashSet) Union(other *HashSet) *HashSet {
-	result := NewHashSet(set.Size() + other.Size())
+func (set *HashSet[T]) Union(other Set[T]) *HashSet[T] {
+	result := NewHashSetOf[T](set.Size() + other.Size())
 	for i := range set.data {
 		result.Add(i)
 	}
-	for i := range other.data {
-		result.Add(i)
+	if o, ok := other.(*HashSet[T]); ok {
+		for i := range o.data {
+			result.Add(i)
+		}
+		return result
 	}
-	return result
+	return result.Add(other.All()...)
 }
 
 // Intersection returns a new set with integers common to both sets.
-func (set *HashSet) Intersection(other *HashSet) *HashSet {
-	result := &HashSet{}
+func (set *HashSet[T]) Intersection(other Set[T]) *HashSet[T] {
+	result := &HashSet[T]{}
+	o, ok := other.(*HashSet[T])
 	// always loop over the smallest set
-	if set.Size() < other.Size() {
+	if !ok || set.Size() < o.Size() {
 		result.init(set.Size())
 		for i := range set.data {
 			if other.Contains(i) {
@@ -119,8 +218,8 @@ func (set *HashSet) Intersection(other *HashSet) *HashSet {
 			}
 		}
 	} else {
-		result.init(other.Size())
-		for i := range other.data {
+		result.init(o.Size())
+		for i := range o.data {
 			if set.Contains(i) {
 				result.Add(i)
 			}
@@ -130,8 +229,8 @@ func (set *HashSet) Intersection(other *HashSet) *HashSet {
 }
 
 // Difference returns a new set with the integers in set which are not in other.
-func (set *HashSet) Difference(other *HashSet) *HashSet {
-	result := NewHashSet(set.Size())
+func (set *HashSet[T]) Difference(other Set[T]) *HashSet[T] {
+	result := NewHashSetOf[T](set.Size())
 	for i := range set.data {
 		if !other.Contains(i) {
 			result.Add(i)
@@ -142,23 +241,189 @@ func (set *HashSet) Difference(other *HashSet) *HashSet {
 
 // SymetricDifference returns a new set with the integers in current and other,
 // but not in both.
-func (set *HashSet) SymetricDifference(other *HashSet) *HashSet {
+func (set *HashSet[T]) SymetricDifference(other Set[T]) *HashSet[T] {
 	a := set.Difference(other)
-	b := other.Difference(set)
+	b := other.DifferenceSet(set)
 	return a.Union(b)
 }
 
+// UnionWith adds the integers in other to the set. It is the in-place variant
+// of Union.
+func (set *HashSet[T]) UnionWith(other Set[T]) *HashSet[T] {
+	for i := range other.Values() {
+		set.data[i] = true
+	}
+	return set
+}
+
+// IntersectWith removes the integers which are not in other from the set. It
+// is the in-place variant of Intersection.
+func (set *HashSet[T]) IntersectWith(other Set[T]) *HashSet[T] {
+	for i := range set.data {
+		if !other.Contains(i) {
+			delete(set.data, i)
+		}
+	}
+	return set
+}
+
+// DifferenceWith removes the integers in other from the set. It is the
+// in-place variant of Difference.
+func (set *HashSet[T]) DifferenceWith(other Set[T]) *HashSet[T] {
+	if other.Size() < len(set.data) {
+		for i := range other.Values() {
+			delete(set.data, i)
+		}
+		return set
+	}
+	for i := range set.data {
+		if other.Contains(i) {
+			delete(set.data, i)
+		}
+	}
+	return set
+}
+
+// SymmetricDifferenceWith adds the integers in other which are not in the set,
+// and removes those which are. It is the in-place variant of
+// SymetricDifference.
+func (set *HashSet[T]) SymmetricDifferenceWith(other Set[T]) *HashSet[T] {
+	if o, ok := other.(*HashSet[T]); ok && o == set {
+		return set.Clear()
+	}
+	for i := range other.Values() {
+		if set.data[i] {
+			delete(set.data, i)
+		} else {
+			set.data[i] = true
+		}
+	}
+	return set
+}
+
+// IntersectionCount returns the number of integers common to both sets,
+// without building the intersection.
+func (set *HashSet[T]) IntersectionCount(other Set[T]) int {
+	return intersectionCount[T](set, other)
+}
+
+// UnionCount returns the number of integers in the union of two sets, without
+// building the union.
+func (set *HashSet[T]) UnionCount(other Set[T]) int {
+	return set.Size() + other.Size() - set.IntersectionCount(other)
+}
+
+// DifferenceCount returns the number of integers in set which are not in
+// other, without building the difference.
+func (set *HashSet[T]) DifferenceCount(other Set[T]) int {
+	return set.Size() - set.IntersectionCount(other)
+}
+
+// SymmetricDifferenceCount returns the number of integers in current and
+// other, but not in both, without building the symmetric difference.
+func (set *HashSet[T]) SymmetricDifferenceCount(other Set[T]) int {
+	return set.Size() + other.Size() - 2*set.IntersectionCount(other)
+}
+
+// Intersects returns true if the sets have at least one integer in common.
+func (set *HashSet[T]) Intersects(other Set[T]) bool {
+	return intersects[T](set, other)
+}
+
 // Clone returns a new set which is a clone of current set.
-func (set *HashSet) Clone() *HashSet {
-	result := NewHashSet(set.Size())
+func (set *HashSet[T]) Clone() *HashSet[T] {
+	result := NewHashSetOf[T](set.Size())
 	for i := range set.data {
 		result.Add(i)
 	}
 	return result
 }
 
+// Insert adds one or more integers to the set. It is the Set adapter for Add.
+func (set *HashSet[T]) Insert(ints ...T) {
+	set.Add(ints...)
+}
+
+// Delete removes one or more integers from the set. It is the Set adapter
+// for Remove.
+func (set *HashSet[T]) Delete(ints ...T) {
+	set.Remove(ints...)
+}
+
+// CloneSet is the Set adapter for Clone.
+func (set *HashSet[T]) CloneSet() Set[T] {
+	return set.Clone()
+}
+
+// UnionSet is the Set adapter for Union.
+func (set *HashSet[T]) UnionSet(other Set[T]) Set[T] {
+	return set.Union(other)
+}
+
+// IntersectionSet is the Set adapter for Intersection.
+func (set *HashSet[T]) IntersectionSet(other Set[T]) Set[T] {
+	return set.Intersection(other)
+}
+
+// DifferenceSet is the Set adapter for Difference.
+func (set *HashSet[T]) DifferenceSet(other Set[T]) Set[T] {
+	return set.Difference(other)
+}
+
+// SymetricDifferenceSet is the Set adapter for SymetricDifference.
+func (set *HashSet[T]) SymetricDifferenceSet(other Set[T]) Set[T] {
+	return set.SymetricDifference(other)
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (set *HashSet[T]) MarshalBinary() ([]byte, error) {
+	return marshalBinary(tagHashSet, set.All()), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface. The
+// data may have been marshalled from any set type.
+func (set *HashSet[T]) UnmarshalBinary(data []byte) error {
+	ints, err := unmarshalBinary[T](data)
+	if err != nil {
+		return err
+	}
+	return set.load(ints)
+}
+
+// MarshalJSON implements the json.Marshaler interface. The set is marshalled
+// as an array of integers in ascending order.
+func (set *HashSet[T]) MarshalJSON() ([]byte, error) {
+	return marshalJSON(set.All()), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It accepts an array
+// of integers or a string in the form produced by MarshalText.
+func (set *HashSet[T]) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, set.load, expanded(set.load))
+}
+
+// MarshalText implements the encoding.TextMarshaler interface. The set is
+// marshalled as a comma separated list of integers and ranges of integers in
+// ascending order, e.g. "1-5,9".
+func (set *HashSet[T]) MarshalText() ([]byte, error) {
+	return marshalText(set.All()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface. It accepts
+// the form produced by MarshalText as well as the one produced by String.
+func (set *HashSet[T]) UnmarshalText(text []byte) error {
+	return unmarshalText(text, expanded(set.load))
+}
+
+// load replaces the contents of the set with the given integers.
+func (set *HashSet[T]) load(ints []T) error {
+	set.init(len(ints))
+	set.Add(ints...)
+	return nil
+}
+
 // String implements the Stringer interface for HashSet.
-func (set *HashSet) String() string {
+func (set *HashSet[T]) String() string {
 	items := make([]string, 0, len(set.data))
 
 	for k := range set.data {
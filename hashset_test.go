@@ -26,6 +26,33 @@ func TestHashSetRemove(t *testing.T) {
 	specs.Expect(set.Contains(1, 3), false)
 }
 
+func TestHashSetRanges(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewHashSet(200).AddRange(10, 140)
+	specs.Expect(set.Size(), 131)
+	specs.Expect(set.Contains(10, 63, 64, 140), true)
+	specs.Expect(set.Contains(9), false)
+	specs.Expect(set.Contains(141), false)
+
+	set.RemoveRange(20, 129)
+	specs.Expect(set.Size(), 21)
+	specs.Expect(set.Contains(19, 130), true)
+	specs.Expect(set.Contains(20), false)
+	specs.Expect(set.Contains(129), false)
+
+	set.FlipRange(15, 25)
+	specs.Expect(set.Size(), 22)
+	specs.Expect(set.Contains(14, 20, 25), true)
+	specs.Expect(set.Contains(15), false)
+	specs.Expect(set.Contains(26), false)
+
+	set.RemoveRange(-5, 1000)
+	specs.Expect(set.Size(), 0)
+	set.AddRange(5, 4)
+	specs.Expect(set.Size(), 0)
+}
+
 func TestHashSetContains(t *testing.T) {
 	specs := specs.New(t)
 
@@ -67,6 +94,25 @@ func TestHashSetAll(t *testing.T) {
 	specs.Expect(all[2], 99)
 }
 
+func TestHashSetValues(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewHashSet(10).Add(99, 1, 5)
+	var all []int
+	for i := range set.Values() {
+		all = append(all, i)
+	}
+	sort.Ints(all) // HashSet.Values() doesn't guarantee order of ints
+	specs.Expect(all, []int{1, 5, 99})
+
+	all = nil
+	for i := range set.Values() {
+		all = append(all, i)
+		break
+	}
+	specs.Expect(len(all), 1)
+}
+
 func TestHashSetEqual(t *testing.T) {
 	specs := specs.New(t)
 
@@ -142,6 +188,24 @@ func TestHashSetClone(t *testing.T) {
 	specs.Expect(setB.Equal(setA), true)
 }
 
+func TestHashSetInPlace(t *testing.T) {
+	specs := specs.New(t)
+
+	a := NewHashSet(20).Add(1, 2, 4, 10)
+	for _, b := range []IntSet{NewHashSet(20).Add(2, 3, 10, 15), NewBitSet(20).Add(2, 3, 10, 15)} {
+		specs.Expect(a.Clone().UnionWith(b).Equal(a.Union(b)), true)
+		specs.Expect(a.Clone().IntersectWith(b).Equal(a.Intersection(b)), true)
+		specs.Expect(a.Clone().DifferenceWith(b).Equal(a.Difference(b)), true)
+		specs.Expect(a.Clone().SymmetricDifferenceWith(b).Equal(a.SymetricDifference(b)), true)
+	}
+
+	c := a.Clone()
+	specs.Expect(c.UnionWith(c).Equal(a), true)
+	specs.Expect(c.IntersectWith(c).Equal(a), true)
+	specs.Expect(c.Clone().DifferenceWith(c).Size(), 0)
+	specs.Expect(c.SymmetricDifferenceWith(c).Size(), 0)
+}
+
 // Benchmarks
 
 func BenchmarkHashSetAdd(b *testing.B) {
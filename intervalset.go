@@ -0,0 +1,737 @@
+package intset
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"iter"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Interval is the range of integers from Lo to Hi, inclusive.
+type Interval[T Integer] struct {
+	Lo, Hi T
+}
+
+// size returns the number of integers in the interval. It panics if the
+// number does not fit in an int.
+func (r Interval[T]) size() int {
+	n, ok := r.count()
+	if !ok {
+		panic(errTooLarge.Error())
+	}
+	return n
+}
+
+// count returns the number of integers in the interval. The second return
+// value is false if the number does not fit in an int.
+func (r Interval[T]) count() (int, bool) {
+	n := uint64(r.Hi) - uint64(r.Lo)
+	if n >= math.MaxInt {
+		return 0, false
+	}
+	return int(n) + 1, true
+}
+
+// errTooLarge is the error for an IntervalSet which would hold more integers
+// than its size can count.
+var errTooLarge = errors.New("intset: IntervalSet cannot hold more than math.MaxInt integers")
+
+// IntervalSet is an integer set stored as sorted, disjoint and non-adjacent
+// intervals of consecutive integers, or runs. Its memory scales with the
+// number of runs rather than with the number of integers, so it suits sets of
+// clustered integers, such as all IDs from 1e6 to 5e6 except a few.
+//
+// It can hold at most math.MaxInt integers, so that its size fits in an int.
+// Adding more panics, and unmarshaling more returns an error.
+type IntervalSet[T Integer] struct {
+	runs []Interval[T]
+	size int
+
+	// ranks holds the number of integers before each run. It is built by
+	// Rank and Select, and reset when the set is modified.
+	ranks rankIndex
+}
+
+// NewIntervalSet is the constructor for an IntervalSet of ints.
+func NewIntervalSet(max int) *IntervalSet[int] {
+	return NewIntervalSetOf[int](max)
+}
+
+// NewIntervalSetOf is the constructor for an IntervalSet of any integer type.
+func NewIntervalSetOf[T Integer](max int) *IntervalSet[T] {
+	return new(IntervalSet[T]).init(max)
+}
+
+func (set *IntervalSet[T]) init(max int) *IntervalSet[T] {
+	set.runs = nil
+	set.size = 0
+	set.ranks.reset()
+	return set
+}
+
+// setRuns replaces the runs of the set.
+func (set *IntervalSet[T]) setRuns(runs []Interval[T]) *IntervalSet[T] {
+	size := sizeOfRuns(runs)
+	set.runs = runs
+	set.size = size
+	set.ranks.reset()
+	return set
+}
+
+// Clear the set.
+func (set *IntervalSet[T]) Clear() *IntervalSet[T] {
+	set.runs = set.runs[:0]
+	set.size = 0
+	set.ranks.reset()
+	return set
+}
+
+// Size returns the number of integers in the set.
+func (set *IntervalSet[T]) Size() int {
+	return set.size
+}
+
+// Add one or more integers to the set.
+func (set *IntervalSet[T]) Add(ints ...T) *IntervalSet[T] {
+	for _, v := range ints {
+		set.addRange(v, v)
+	}
+	return set
+}
+
+// Remove one or more integers from the set.
+func (set *IntervalSet[T]) Remove(ints ...T) *IntervalSet[T] {
+	for _, v := range ints {
+		set.removeRange(v, v)
+	}
+	return set
+}
+
+// AddRange adds the integers from lo to hi, inclusive, to the set.
+func (set *IntervalSet[T]) AddRange(lo, hi T) *IntervalSet[T] {
+	if hi >= lo {
+		set.addRange(lo, hi)
+	}
+	return set
+}
+
+// RemoveRange removes the integers from lo to hi, inclusive, from the set.
+func (set *IntervalSet[T]) RemoveRange(lo, hi T) *IntervalSet[T] {
+	if hi >= lo {
+		set.removeRange(lo, hi)
+	}
+	return set
+}
+
+// FlipRange adds the integers from lo to hi, inclusive, which are not in the
+// set, and removes those which are.
+func (set *IntervalSet[T]) FlipRange(lo, hi T) *IntervalSet[T] {
+	if hi >= lo {
+		set.setRuns(xorRuns(set.runs, []Interval[T]{{lo, hi}}))
+	}
+	return set
+}
+
+// search returns the position of the first run which ends at or after v.
+func (set *IntervalSet[T]) search(v T) int {
+	return sort.Search(len(set.runs), func(k int) bool { return set.runs[k].Hi >= v })
+}
+
+// addRange adds the integers from lo to hi, merging the runs which overlap or
+// are adjacent to the range.
+func (set *IntervalSet[T]) addRange(lo, hi T) {
+	i := sort.Search(len(set.runs), func(k int) bool {
+		return set.runs[k].Hi >= lo || set.runs[k].Hi+1 == lo
+	})
+	j := i
+	for j < len(set.runs) && (set.runs[j].Lo <= hi || set.runs[j].Lo-1 == hi) {
+		j++
+	}
+	r := Interval[T]{lo, hi}
+	if i < j {
+		if set.runs[i].Lo < lo {
+			r.Lo = set.runs[i].Lo
+		}
+		if set.runs[j-1].Hi > hi {
+			r.Hi = set.runs[j-1].Hi
+		}
+	}
+	n, added := set.size-sizeOfRuns(set.runs[i:j]), r.size()
+	if n > math.MaxInt-added {
+		panic(errTooLarge.Error())
+	}
+	set.size = n + added
+	set.runs = slices.Replace(set.runs, i, j, r)
+	set.ranks.reset()
+}
+
+// removeRange removes the integers from lo to hi, splitting the runs which
+// overlap the range.
+func (set *IntervalSet[T]) removeRange(lo, hi T) {
+	i := set.search(lo)
+	j := i
+	for j < len(set.runs) && set.runs[j].Lo <= hi {
+		j++
+	}
+	if i == j {
+		return
+	}
+	var pieces []Interval[T]
+	if r := set.runs[i]; r.Lo < lo {
+		pieces = append(pieces, Interval[T]{r.Lo, lo - 1})
+	}
+	if r := set.runs[j-1]; r.Hi > hi {
+		pieces = append(pieces, Interval[T]{hi + 1, r.Hi})
+	}
+	set.size += sizeOfRuns(pieces) - sizeOfRuns(set.runs[i:j])
+	set.runs = slices.Replace(set.runs, i, j, pieces...)
+	set.ranks.reset()
+}
+
+// Runs returns the runs of consecutive integers in the set, in ascending
+// order.
+func (set *IntervalSet[T]) Runs() []Interval[T] {
+	return append([]Interval[T](nil), set.runs...)
+}
+
+// Gaps returns the intervals of integers which are not in the set, between
+// its smallest and its largest integer, in ascending order.
+func (set *IntervalSet[T]) Gaps() []Interval[T] {
+	var gaps []Interval[T]
+	for k := 1; k < len(set.runs); k++ {
+		gaps = append(gaps, Interval[T]{set.runs[k-1].Hi + 1, set.runs[k].Lo - 1})
+	}
+	return gaps
+}
+
+// All returns a slice of all the integers in the set, in ascending order.
+func (set *IntervalSet[T]) All() []T {
+	var all []T
+	for v := range set.Values() {
+		all = append(all, v)
+	}
+	return all
+}
+
+// Values returns an iterator over the integers in the set in ascending order.
+func (set *IntervalSet[T]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, r := range set.runs {
+			for v := r.Lo; ; v++ {
+				if !yield(v) {
+					return
+				}
+				if v == r.Hi {
+					break
+				}
+			}
+		}
+	}
+}
+
+// Backward returns an iterator over the integers in the set in descending
+// order.
+func (set *IntervalSet[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for k := len(set.runs) - 1; k >= 0; k-- {
+			for v := set.runs[k].Hi; ; v-- {
+				if !yield(v) {
+					return
+				}
+				if v == set.runs[k].Lo {
+					break
+				}
+			}
+		}
+	}
+}
+
+// Min returns the smallest integer in the set. The second return value is
+// false if the set is empty.
+func (set *IntervalSet[T]) Min() (T, bool) {
+	if len(set.runs) == 0 {
+		return 0, false
+	}
+	return set.runs[0].Lo, true
+}
+
+// Max returns the largest integer in the set. The second return value is false
+// if the set is empty.
+func (set *IntervalSet[T]) Max() (T, bool) {
+	if len(set.runs) == 0 {
+		return 0, false
+	}
+	return set.runs[len(set.runs)-1].Hi, true
+}
+
+// NextAfter returns the smallest integer in the set which is greater than v.
+// The second return value is false if there is no such integer.
+func (set *IntervalSet[T]) NextAfter(v T) (T, bool) {
+	k := sort.Search(len(set.runs), func(k int) bool { return set.runs[k].Hi > v })
+	switch {
+	case k == len(set.runs):
+		return 0, false
+	case set.runs[k].Lo > v:
+		return set.runs[k].Lo, true
+	}
+	return v + 1, true
+}
+
+// PrevBefore returns the largest integer in the set which is less than v. The
+// second return value is false if there is no such integer.
+func (set *IntervalSet[T]) PrevBefore(v T) (T, bool) {
+	k := sort.Search(len(set.runs), func(k int) bool { return set.runs[k].Lo >= v }) - 1
+	switch {
+	case k < 0:
+		return 0, false
+	case set.runs[k].Hi < v:
+		return set.runs[k].Hi, true
+	}
+	return v - 1, true
+}
+
+// Contains returns true if all ints are in the set, otherwise false.
+func (set *IntervalSet[T]) Contains(ints ...T) bool {
+	for _, v := range ints {
+		if k := set.search(v); k == len(set.runs) || set.runs[k].Lo > v {
+			return false
+		}
+	}
+	return true
+}
+
+// buildRanks builds the rank index: the number of integers before each run.
+func (set *IntervalSet[T]) buildRanks() []int {
+	ranks := make([]int, len(set.runs))
+	var n int
+	for k, r := range set.runs {
+		ranks[k] = n
+		n += r.size()
+	}
+	return ranks
+}
+
+// Rank returns the number of integers in the set which are less than or equal
+// to v. Like Select, it takes logarithmic time in the number of runs, using an
+// index which is reset when the set is modified.
+func (set *IntervalSet[T]) Rank(v T) int {
+	k := set.search(v)
+	if k == len(set.runs) {
+		return set.size
+	}
+	n := set.ranks.get(set.buildRanks)[k]
+	if r := set.runs[k]; r.Lo <= v {
+		n += Interval[T]{r.Lo, v}.size()
+	}
+	return n
+}
+
+// Select returns the k-th smallest integer in the set, counting from 0. The
+// second return value is false if k is not less than the size of the set.
+func (set *IntervalSet[T]) Select(k int) (T, bool) {
+	if k < 0 || k >= set.size {
+		return 0, false
+	}
+	ranks := set.ranks.get(set.buildRanks)
+	j := sort.Search(len(ranks), func(j int) bool { return ranks[j] > k }) - 1
+	return set.runs[j].Lo + T(k-ranks[j]), true
+}
+
+// Equal checks if two sets both contains all the same items.
+func (set *IntervalSet[T]) Equal(other Set[T]) bool {
+	if o, ok := other.(*IntervalSet[T]); ok {
+		return slices.Equal(set.runs, o.runs)
+	}
+	return set.Size() == other.Size() && set.SubsetOf(other)
+}
+
+// SubsetOf checks if all items in set are also present in other set.
+func (set *IntervalSet[T]) SubsetOf(other Set[T]) bool {
+	if o, ok := other.(*IntervalSet[T]); ok {
+		for _, r := range set.runs {
+			k := o.search(r.Lo)
+			if k == len(o.runs) || o.runs[k].Lo > r.Lo || o.runs[k].Hi < r.Hi {
+				return false
+			}
+		}
+		return true
+	}
+	if set.Size() > other.Size() {
+		return false
+	}
+	for v := range set.Values() {
+		if !other.Contains(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// SupersetOf checks if a set is a superset of another set.
+func (set *IntervalSet[T]) SupersetOf(other Set[T]) bool {
+	return other.SubsetOf(set)
+}
+
+// Union returns a new set which is the union of two sets.
+func (set *IntervalSet[T]) Union(other Set[T]) *IntervalSet[T] {
+	return new(IntervalSet[T]).setRuns(unionRuns(set.runs, runsOf(other)))
+}
+
+// Intersection returns a new set with integers common to both sets.
+func (set *IntervalSet[T]) Intersection(other Set[T]) *IntervalSet[T] {
+	return new(IntervalSet[T]).setRuns(intersectRuns(set.runs, runsOf(other)))
+}
+
+// Difference returns a new set with the integers in set which are not in other.
+func (set *IntervalSet[T]) Difference(other Set[T]) *IntervalSet[T] {
+	return new(IntervalSet[T]).setRuns(differenceRuns(set.runs, runsOf(other)))
+}
+
+// SymetricDifference returns a new set with the integers in current and other,
+// but not in both.
+func (set *IntervalSet[T]) SymetricDifference(other Set[T]) *IntervalSet[T] {
+	return new(IntervalSet[T]).setRuns(xorRuns(set.runs, runsOf(other)))
+}
+
+// UnionWith adds the integers in other to the set. It is the in-place variant
+// of Union.
+func (set *IntervalSet[T]) UnionWith(other Set[T]) *IntervalSet[T] {
+	return set.setRuns(unionRuns(set.runs, runsOf(other)))
+}
+
+// IntersectWith removes the integers which are not in other from the set. It
+// is the in-place variant of Intersection.
+func (set *IntervalSet[T]) IntersectWith(other Set[T]) *IntervalSet[T] {
+	return set.setRuns(intersectRuns(set.runs, runsOf(other)))
+}
+
+// DifferenceWith removes the integers in other from the set. It is the
+// in-place variant of Difference.
+func (set *IntervalSet[T]) DifferenceWith(other Set[T]) *IntervalSet[T] {
+	return set.setRuns(differenceRuns(set.runs, runsOf(other)))
+}
+
+// SymmetricDifferenceWith adds the integers in other which are not in the set,
+// and removes those which are. It is the in-place variant of
+// SymetricDifference.
+func (set *IntervalSet[T]) SymmetricDifferenceWith(other Set[T]) *IntervalSet[T] {
+	return set.setRuns(xorRuns(set.runs, runsOf(other)))
+}
+
+// IntersectionCount returns the number of integers common to both sets,
+// without building the intersection.
+func (set *IntervalSet[T]) IntersectionCount(other Set[T]) int {
+	o, ok := other.(*IntervalSet[T])
+	if !ok {
+		return intersectionCount[T](set, other)
+	}
+	var n int
+	overlapRuns(set.runs, o.runs, func(r Interval[T]) bool {
+		n += r.size()
+		return true
+	})
+	return n
+}
+
+// UnionCount returns the number of integers in the union of two sets, without
+// building the union.
+func (set *IntervalSet[T]) UnionCount(other Set[T]) int {
+	return set.Size() + other.Size() - set.IntersectionCount(other)
+}
+
+// DifferenceCount returns the number of integers in set which are not in
+// other, without building the difference.
+func (set *IntervalSet[T]) DifferenceCount(other Set[T]) int {
+	return set.Size() - set.IntersectionCount(other)
+}
+
+// SymmetricDifferenceCount returns the number of integers in current and
+// other, but not in both, without building the symmetric difference.
+func (set *IntervalSet[T]) SymmetricDifferenceCount(other Set[T]) int {
+	return set.Size() + other.Size() - 2*set.IntersectionCount(other)
+}
+
+// Intersects returns true if the sets have at least one integer in common.
+func (set *IntervalSet[T]) Intersects(other Set[T]) bool {
+	o, ok := other.(*IntervalSet[T])
+	if !ok {
+		return intersects[T](set, other)
+	}
+	var found bool
+	overlapRuns(set.runs, o.runs, func(Interval[T]) bool {
+		found = true
+		return false
+	})
+	return found
+}
+
+// Clone returns a new set which is a clone of current set.
+func (set *IntervalSet[T]) Clone() *IntervalSet[T] {
+	return &IntervalSet[T]{runs: slices.Clone(set.runs), size: set.size}
+}
+
+// runsOf returns the runs of consecutive integers in the set, in ascending
+// order. The slice must not be modified, as it may be the backing slice of an
+// IntervalSet.
+func runsOf[T Integer](set Set[T]) []Interval[T] {
+	if o, ok := set.(*IntervalSet[T]); ok {
+		return o.runs
+	}
+	var runs []Interval[T]
+	for _, v := range sortedOf(set) {
+		if n := len(runs); n > 0 && runs[n-1].Hi+1 == v {
+			runs[n-1].Hi = v
+			continue
+		}
+		runs = append(runs, Interval[T]{v, v})
+	}
+	return runs
+}
+
+// sizeOfRuns returns the number of integers in the runs. It panics if the
+// number does not fit in an int.
+func sizeOfRuns[T Integer](runs []Interval[T]) int {
+	n, ok := countOfRuns(runs)
+	if !ok {
+		panic(errTooLarge.Error())
+	}
+	return n
+}
+
+// countOfRuns returns the number of integers in the runs. The second return
+// value is false if the number does not fit in an int.
+func countOfRuns[T Integer](runs []Interval[T]) (int, bool) {
+	var n int
+	for _, r := range runs {
+		m, ok := r.count()
+		if !ok || n > math.MaxInt-m {
+			return 0, false
+		}
+		n += m
+	}
+	return n, true
+}
+
+// unionRuns returns the union of the sorted runs a and b, by merging them
+// linearly.
+func unionRuns[T Integer](a, b []Interval[T]) []Interval[T] {
+	result := make([]Interval[T], 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		var r Interval[T]
+		if j == len(b) || i < len(a) && a[i].Lo <= b[j].Lo {
+			r = a[i]
+			i++
+		} else {
+			r = b[j]
+			j++
+		}
+		if n := len(result); n > 0 && (r.Lo <= result[n-1].Hi || result[n-1].Hi+1 == r.Lo) {
+			if r.Hi > result[n-1].Hi {
+				result[n-1].Hi = r.Hi
+			}
+			continue
+		}
+		result = append(result, r)
+	}
+	return result
+}
+
+// overlapRuns calls fn for each overlap of a run in a with a run in b, in
+// ascending order, until fn returns false.
+func overlapRuns[T Integer](a, b []Interval[T], fn func(Interval[T]) bool) {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		r := a[i]
+		if b[j].Lo > r.Lo {
+			r.Lo = b[j].Lo
+		}
+		if b[j].Hi < r.Hi {
+			r.Hi = b[j].Hi
+		}
+		if r.Lo <= r.Hi && !fn(r) {
+			return
+		}
+		if a[i].Hi < b[j].Hi {
+			i++
+		} else {
+			j++
+		}
+	}
+}
+
+// intersectRuns returns the intersection of the sorted runs a and b.
+func intersectRuns[T Integer](a, b []Interval[T]) []Interval[T] {
+	var result []Interval[T]
+	overlapRuns(a, b, func(r Interval[T]) bool {
+		result = append(result, r)
+		return true
+	})
+	return result
+}
+
+// differenceRuns returns the parts of the sorted runs a which are not in the
+// sorted runs b.
+func differenceRuns[T Integer](a, b []Interval[T]) []Interval[T] {
+	var result []Interval[T]
+	j := 0
+	for _, r := range a {
+		for j < len(b) && b[j].Hi < r.Lo {
+			j++
+		}
+		rest := true
+		for k := j; k < len(b) && b[k].Lo <= r.Hi; k++ {
+			if b[k].Lo > r.Lo {
+				result = append(result, Interval[T]{r.Lo, b[k].Lo - 1})
+			}
+			if b[k].Hi >= r.Hi {
+				rest = false
+				break
+			}
+			r.Lo = b[k].Hi + 1
+		}
+		if rest {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+// xorRuns returns the integers in one of the sorted runs a and b, but not in
+// both.
+func xorRuns[T Integer](a, b []Interval[T]) []Interval[T] {
+	return unionRuns(differenceRuns(a, b), differenceRuns(b, a))
+}
+
+// Insert adds one or more integers to the set. It is the Set adapter for Add.
+func (set *IntervalSet[T]) Insert(ints ...T) {
+	set.Add(ints...)
+}
+
+// Delete removes one or more integers from the set. It is the Set adapter
+// for Remove.
+func (set *IntervalSet[T]) Delete(ints ...T) {
+	set.Remove(ints...)
+}
+
+// CloneSet is the Set adapter for Clone.
+func (set *IntervalSet[T]) CloneSet() Set[T] {
+	return set.Clone()
+}
+
+// UnionSet is the Set adapter for Union.
+func (set *IntervalSet[T]) UnionSet(other Set[T]) Set[T] {
+	return set.Union(other)
+}
+
+// IntersectionSet is the Set adapter for Intersection.
+func (set *IntervalSet[T]) IntersectionSet(other Set[T]) Set[T] {
+	return set.Intersection(other)
+}
+
+// DifferenceSet is the Set adapter for Difference.
+func (set *IntervalSet[T]) DifferenceSet(other Set[T]) Set[T] {
+	return set.Difference(other)
+}
+
+// SymetricDifferenceSet is the Set adapter for SymetricDifference.
+func (set *IntervalSet[T]) SymetricDifferenceSet(other Set[T]) Set[T] {
+	return set.SymetricDifference(other)
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (set *IntervalSet[T]) MarshalBinary() ([]byte, error) {
+	return marshalBinary(tagIntervalSet, set.All()), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface. The
+// data may have been marshalled from any set type.
+func (set *IntervalSet[T]) UnmarshalBinary(data []byte) error {
+	ints, err := unmarshalBinary[T](data)
+	if err != nil {
+		return err
+	}
+	return set.load(ints)
+}
+
+// MarshalJSON implements the json.Marshaler interface. The set is marshalled
+// as an array of integers in ascending order.
+func (set *IntervalSet[T]) MarshalJSON() ([]byte, error) {
+	return marshalJSON(set.All()), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It accepts an array
+// of integers or a string in the form produced by MarshalText.
+func (set *IntervalSet[T]) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, set.load, set.loadRuns)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface. The set is
+// marshalled as a comma separated list of integers and ranges of integers in
+// ascending order, e.g. "1-5,9". It is written directly from the runs.
+func (set *IntervalSet[T]) MarshalText() ([]byte, error) {
+	var buf []byte
+	for k, r := range set.runs {
+		if k > 0 {
+			buf = append(buf, ',')
+		}
+		buf = appendInt(buf, r.Lo)
+		switch {
+		case r.Hi == r.Lo:
+		case r.Hi-r.Lo == 1:
+			buf = appendInt(append(buf, ','), r.Hi)
+		default:
+			buf = appendInt(append(buf, '-'), r.Hi)
+		}
+	}
+	return buf, nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface. It accepts
+// the form produced by MarshalText as well as the one produced by String.
+func (set *IntervalSet[T]) UnmarshalText(text []byte) error {
+	return unmarshalText(text, set.loadRuns)
+}
+
+// load replaces the contents of the set with the given integers.
+func (set *IntervalSet[T]) load(ints []T) error {
+	set.setRuns(runsOf[T](&ArraySet[T]{data: sortedSet(ints)}))
+	return nil
+}
+
+// loadRuns replaces the contents of the set with the integers of the given
+// ranges. The ranges are sorted and merged first, so that an error is
+// returned, and the set left unchanged, if they hold too many integers.
+func (set *IntervalSet[T]) loadRuns(runs []Interval[T]) error {
+	runs = slices.Clone(runs)
+	slices.SortFunc(runs, func(a, b Interval[T]) int { return cmp.Compare(a.Lo, b.Lo) })
+	merged := runs[:0]
+	for _, r := range runs {
+		if k := len(merged) - 1; k >= 0 && (merged[k].Hi >= r.Lo || merged[k].Hi+1 == r.Lo) {
+			if r.Hi > merged[k].Hi {
+				merged[k].Hi = r.Hi
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	if _, ok := countOfRuns(merged); !ok {
+		return errTooLarge
+	}
+	set.setRuns(merged)
+	return nil
+}
+
+// String implements the Stringer interface for IntervalSet.
+func (set *IntervalSet[T]) String() string {
+	items := make([]string, 0, set.size)
+
+	for v := range set.Values() {
+		items = append(items, fmt.Sprintf("%v", v))
+	}
+	return fmt.Sprintf("Set{%s}", strings.Join(items, ", "))
+}
@@ -0,0 +1,258 @@
+package intset
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/knakk/specs"
+)
+
+type iv = Interval[int]
+
+func TestIntervalSetAdd(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewIntervalSet(0).Add(5, 1, 2, 5, 9)
+	specs.Expect(set.Runs(), []iv{{1, 2}, {5, 5}, {9, 9}})
+	specs.Expect(set.Size(), 4)
+
+	// adjacent integers merge runs
+	set.Add(3, 4, 8)
+	specs.Expect(set.Runs(), []iv{{1, 5}, {8, 9}})
+	set.Add(7)
+	specs.Expect(set.Runs(), []iv{{1, 5}, {7, 9}})
+	set.Add(6)
+	specs.Expect(set.Runs(), []iv{{1, 9}})
+	specs.Expect(set.Size(), 9)
+}
+
+func TestIntervalSetRemove(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewIntervalSet(0).AddRange(1, 10)
+	set.Remove(5)
+	specs.Expect(set.Runs(), []iv{{1, 4}, {6, 10}})
+	set.Remove(1, 10, 11)
+	specs.Expect(set.Runs(), []iv{{2, 4}, {6, 9}})
+	set.Remove(2, 3, 4)
+	specs.Expect(set.Runs(), []iv{{6, 9}})
+	specs.Expect(set.Size(), 4)
+	specs.Expect(set.Contains(5), false)
+}
+
+func TestIntervalSetRanges(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewIntervalSet(0).Add(1, 200).AddRange(10, 140)
+	specs.Expect(set.Size(), 133)
+	specs.Expect(set.Contains(1, 10, 63, 64, 140, 200), true)
+	specs.Expect(set.Contains(9), false)
+	specs.Expect(set.Contains(141), false)
+
+	set.RemoveRange(20, 129)
+	specs.Expect(set.Size(), 23)
+	specs.Expect(set.Runs(), []iv{{1, 1}, {10, 19}, {130, 140}, {200, 200}})
+
+	set.FlipRange(15, 25)
+	specs.Expect(set.Size(), 24)
+	specs.Expect(set.Runs(), []iv{{1, 1}, {10, 14}, {20, 25}, {130, 140}, {200, 200}})
+
+	set.AddRange(2, 9)
+	specs.Expect(set.Runs(), []iv{{1, 14}, {20, 25}, {130, 140}, {200, 200}})
+
+	set.RemoveRange(-5, 1000)
+	specs.Expect(set.Size(), 0)
+	set.AddRange(5, 4)
+	specs.Expect(set.Size(), 0)
+
+	bytes := NewIntervalSetOf[int8](0).AddRange(-128, 127).Remove(0)
+	specs.Expect(bytes.Size(), 255)
+	specs.Expect(bytes.Runs(), []Interval[int8]{{-128, -1}, {1, 127}})
+	bytes.FlipRange(-128, 127)
+	specs.Expect(bytes.All(), []int8{0})
+}
+
+func TestIntervalSetTooLarge(t *testing.T) {
+	specs := specs.New(t)
+
+	// math.MaxInt integers fit, one more does not
+	set := NewIntervalSet(0).AddRange(0, math.MaxInt-1)
+	specs.Expect(set.Size(), math.MaxInt)
+	specs.Expect(set.Rank(math.MaxInt), math.MaxInt)
+	specs.Expect(set.UnmarshalText([]byte("-10--1,-5-4611686018427387903")), nil)
+	specs.Expect(set.Size(), 1<<62+10)
+
+	for _, f := range []func(){
+		func() { NewIntervalSet(0).AddRange(math.MinInt, math.MaxInt) },
+		func() { NewIntervalSet(0).AddRange(0, math.MaxInt-1).Add(-1) },
+		func() { NewIntervalSet(0).Add(-1).FlipRange(0, math.MaxInt-1) },
+		func() { NewIntervalSetOf[uint64](0).AddRange(0, math.MaxUint64) },
+	} {
+		func() {
+			defer func() {
+				specs.Expect(fmt.Sprint(recover()), "intset: IntervalSet cannot hold more than math.MaxInt integers")
+			}()
+			f()
+		}()
+	}
+
+	specs.Expect(set.UnmarshalText([]byte("-9223372036854775808-9223372036854775807")) != nil, true)
+	specs.Expect(set.UnmarshalText([]byte("-1,0-9223372036854775806")) != nil, true)
+	specs.Expect(set.Size(), 1<<62+10)
+	specs.Expect(NewAdaptiveSet(0).UnmarshalText([]byte("-1,0-9223372036854775806")) != nil, true)
+}
+
+func TestIntervalSetRunsAndGaps(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewIntervalSet(0).AddRange(1e6, 5e6).Remove(2e6, 3e6, 3e6+1)
+	specs.Expect(set.Size(), int(4e6-2))
+	specs.Expect(set.Runs(), []iv{{1e6, 2e6 - 1}, {2e6 + 1, 3e6 - 1}, {3e6 + 2, 5e6}})
+	specs.Expect(set.Gaps(), []iv{{2e6, 2e6}, {3e6, 3e6 + 1}})
+	specs.Expect(NewIntervalSet(0).AddRange(1, 5).Gaps() == nil, true)
+
+	data, _ := set.MarshalText()
+	specs.Expect(string(data), "1000000-1999999,2000001-2999999,3000002-5000000")
+}
+
+func TestIntervalSetNavigation(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewIntervalSet(0).AddRange(10, 20).AddRange(30, 40)
+
+	var all []int
+	for i := range set.Backward() {
+		all = append(all, i)
+		if i == 38 {
+			break
+		}
+	}
+	specs.Expect(all, []int{40, 39, 38})
+
+	i, _ := set.NextAfter(15)
+	specs.Expect(i, 16)
+	i, _ = set.NextAfter(20)
+	specs.Expect(i, 30)
+	_, ok := set.NextAfter(40)
+	specs.Expect(ok, false)
+	i, _ = set.PrevBefore(30)
+	specs.Expect(i, 20)
+	i, _ = set.PrevBefore(35)
+	specs.Expect(i, 34)
+	_, ok = set.PrevBefore(10)
+	specs.Expect(ok, false)
+
+	specs.Expect(set.Rank(9), 0)
+	specs.Expect(set.Rank(15), 6)
+	specs.Expect(set.Rank(25), 11)
+	specs.Expect(set.Rank(100), 22)
+	for k, want := range set.All() {
+		i, ok := set.Select(k)
+		specs.Expect(ok, true)
+		specs.Expect(i, want)
+	}
+	_, ok = set.Select(22)
+	specs.Expect(ok, false)
+
+	set.AddRange(0, 4)
+	specs.Expect(set.Rank(15), 11)
+	i, _ = set.Select(5)
+	specs.Expect(i, 10)
+}
+
+func TestIntervalSetAlgebra(t *testing.T) {
+	specs := specs.New(t)
+
+	r := rand.New(rand.NewSource(1))
+	for n := 0; n < 50; n++ {
+		a, b := NewIntervalSet(0), NewIntervalSet(0)
+		ha, hb := NewHashSet(0), NewHashSet(0)
+		for k := 0; k < 5; k++ {
+			lo := r.Intn(200)
+			hi := lo + r.Intn(30)
+			a.AddRange(lo, hi)
+			ha.AddRange(lo, hi)
+			lo = r.Intn(200)
+			hi = lo + r.Intn(30)
+			b.AddRange(lo, hi)
+			hb.AddRange(lo, hi)
+		}
+		specs.Expect(a.Equal(ha), true)
+		specs.Expect(a.Union(b).Equal(ha.Union(hb)), true)
+		specs.Expect(a.Intersection(b).Equal(ha.Intersection(hb)), true)
+		specs.Expect(a.Difference(b).Equal(ha.Difference(hb)), true)
+		specs.Expect(a.SymetricDifference(b).Equal(ha.SymetricDifference(hb)), true)
+		specs.Expect(a.Union(hb).Equal(ha.Union(hb)), true)
+		specs.Expect(a.Difference(hb).Equal(ha.Difference(hb)), true)
+		specs.Expect(a.IntersectionCount(b), ha.IntersectionCount(hb))
+		specs.Expect(a.Intersects(b), ha.Intersects(hb))
+		specs.Expect(a.SubsetOf(b), ha.SubsetOf(hb))
+		specs.Expect(a.Intersection(b).SubsetOf(b), true)
+	}
+}
+
+func TestIntervalSetClone(t *testing.T) {
+	specs := specs.New(t)
+
+	setA := NewIntervalSet(0).Add(9, 3, 1)
+	setB := setA.Clone().Add(2)
+
+	specs.Expect(setA.Runs(), []iv{{1, 1}, {3, 3}, {9, 9}})
+	specs.Expect(setB.Runs(), []iv{{1, 3}, {9, 9}})
+}
+
+func TestIntervalSetInPlace(t *testing.T) {
+	specs := specs.New(t)
+
+	a := NewIntervalSet(0).Add(1, 2, 4, 10)
+	for _, b := range []IntSet{NewIntervalSet(0).Add(2, 3, 10, 15), NewHashSet(20).Add(2, 3, 10, 15)} {
+		specs.Expect(a.Clone().UnionWith(b).Equal(a.Union(b)), true)
+		specs.Expect(a.Clone().IntersectWith(b).Equal(a.Intersection(b)), true)
+		specs.Expect(a.Clone().DifferenceWith(b).Equal(a.Difference(b)), true)
+		specs.Expect(a.Clone().SymmetricDifferenceWith(b).Equal(a.SymetricDifference(b)), true)
+	}
+
+	c := a.Clone()
+	specs.Expect(c.UnionWith(c).Equal(a), true)
+	specs.Expect(c.IntersectWith(c).Equal(a), true)
+	specs.Expect(c.Clone().DifferenceWith(c).Size(), 0)
+	specs.Expect(c.SymmetricDifferenceWith(c).Size(), 0)
+}
+
+// Benchmarks
+
+func BenchmarkIntervalSetAdd(b *testing.B) {
+	set := NewIntervalSet(0)
+	for i := 0; i < b.N; i++ {
+		set.Add(rand.Intn(1000))
+	}
+}
+
+func BenchmarkIntervalSetContains(b *testing.B) {
+	set := NewIntervalSet(0)
+	for i := 0; i < 500; i++ {
+		set.Add(rand.Intn(1000))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		set.Contains(rand.Intn(1000))
+	}
+}
+
+func BenchmarkIntervalSetBigUnion(b *testing.B) {
+	setA := NewIntervalSet(0)
+	setB := NewIntervalSet(0)
+	for i := 0; i < 1000; i++ {
+		lo := rand.Intn(1e7)
+		setA.AddRange(lo, lo+rand.Intn(1000))
+		lo = rand.Intn(1e7)
+		setB.AddRange(lo, lo+rand.Intn(1000))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = setA.Union(setB)
+	}
+}
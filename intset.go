@@ -0,0 +1,278 @@
+package intset
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"math"
+)
+
+// Integer is a constraint that permits any integer type.
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// Set is the interface implemented by all the integer set types in this
+// package. It allows the representation to be chosen at construction time and
+// sets of different types to be combined.
+//
+// The chaining methods of the concrete types (Add, Remove, Clone, Union etc.)
+// return the concrete type, so Set uses adapter methods for those:
+// Insert, Delete, CloneSet, UnionSet, IntersectionSet, DifferenceSet and
+// SymetricDifferenceSet.
+type Set[T Integer] interface {
+	// Size returns the number of integers in the set.
+	Size() int
+
+	// All returns a slice of all the integers in the set.
+	All() []T
+
+	// Values returns an iterator over the integers in the set.
+	Values() iter.Seq[T]
+
+	// Contains returns true if all ints are in the set, otherwise false.
+	Contains(ints ...T) bool
+
+	// Min returns the smallest integer in the set. The second return value
+	// is false if the set is empty.
+	Min() (T, bool)
+
+	// Max returns the largest integer in the set. The second return value is
+	// false if the set is empty.
+	Max() (T, bool)
+
+	// NextAfter returns the smallest integer in the set which is greater
+	// than v. The second return value is false if there is no such integer.
+	NextAfter(v T) (T, bool)
+
+	// PrevBefore returns the largest integer in the set which is less than
+	// v. The second return value is false if there is no such integer.
+	PrevBefore(v T) (T, bool)
+
+	// Equal checks if two sets both contains all the same items.
+	Equal(other Set[T]) bool
+
+	// SubsetOf checks if all items in set are also present in other set.
+	SubsetOf(other Set[T]) bool
+
+	// SupersetOf checks if a set is a superset of another set.
+	SupersetOf(other Set[T]) bool
+
+	// Insert adds one or more integers to the set.
+	Insert(ints ...T)
+
+	// Delete removes one or more integers from the set.
+	Delete(ints ...T)
+
+	// CloneSet returns a new set which is a clone of current set.
+	CloneSet() Set[T]
+
+	// UnionSet returns a new set which is the union of two sets.
+	UnionSet(other Set[T]) Set[T]
+
+	// IntersectionSet returns a new set with integers common to both sets.
+	IntersectionSet(other Set[T]) Set[T]
+
+	// DifferenceSet returns a new set with the integers in set which are not
+	// in other.
+	DifferenceSet(other Set[T]) Set[T]
+
+	// SymetricDifferenceSet returns a new set with the integers in current
+	// and other, but not in both.
+	SymetricDifferenceSet(other Set[T]) Set[T]
+
+	// IntersectionCount returns the number of integers common to both sets,
+	// without building the intersection.
+	IntersectionCount(other Set[T]) int
+
+	// UnionCount returns the number of integers in the union of two sets,
+	// without building the union.
+	UnionCount(other Set[T]) int
+
+	// DifferenceCount returns the number of integers in set which are not in
+	// other, without building the difference.
+	DifferenceCount(other Set[T]) int
+
+	// SymmetricDifferenceCount returns the number of integers in current and
+	// other, but not in both, without building the symmetric difference.
+	SymmetricDifferenceCount(other Set[T]) int
+
+	// Intersects returns true if the sets have at least one integer in
+	// common.
+	Intersects(other Set[T]) bool
+
+	String() string
+}
+
+// IntSet is a Set of ints.
+type IntSet = Set[int]
+
+var (
+	_ IntSet = (*AdaptiveSet[int])(nil)
+	_ IntSet = (*ArraySet[int])(nil)
+	_ IntSet = (*BitSet[int])(nil)
+	_ IntSet = (*BriggsSet[int])(nil)
+	_ IntSet = (*HashSet[int])(nil)
+	_ IntSet = (*IntervalSet[int])(nil)
+	_ IntSet = (*RoaringSet)(nil)
+	_ IntSet = (*SliceSet[int])(nil)
+	_ IntSet = (*SyncSet[int])(nil)
+)
+
+// toInt returns v as an int. The second return value is false if v does not
+// fit in an int.
+func toInt[T Integer](v T) (int, bool) {
+	i := int(v)
+	return i, T(i) == v && (i < 0) == (v < 0)
+}
+
+// offset returns the position of v in the backing storage of the dense set
+// types, which hold the n integers from base. The second return value is false
+// if v is outside that range.
+func offset[T Integer](v T, base, n int) (int, bool) {
+	i, ok := toInt(v)
+	if !ok || i < base {
+		return 0, false
+	}
+	p := uint(i) - uint(base)
+	return int(p), p < uint(n)
+}
+
+// clampRange returns the positions of the range from lo to hi, inclusive,
+// clamped to the n integers from base. The last return value is false if the
+// clamped range is empty.
+func clampRange[T Integer](lo, hi T, base, n int) (int, int, bool) {
+	if hi < lo || n <= 0 {
+		return 0, 0, false
+	}
+	i, ok := toInt(lo)
+	if !ok {
+		return 0, 0, false
+	}
+	j, ok := toInt(hi)
+	if !ok {
+		j = base + n - 1
+	}
+	i, j = max(i, base), min(j, base+n-1)
+	return i - base, j - base, i <= j
+}
+
+// limit is the optional range of integers which a growable set may hold.
+type limit struct {
+	lo, hi int
+	on     bool
+}
+
+// allows returns true if i is within the limit.
+func (l limit) allows(i int) bool {
+	return !l.on || i >= l.lo && i <= l.hi
+}
+
+// maxDense is the largest number of integers the storage of a dense set may
+// grow to hold. It is far more than fits in memory, and only ensures that
+// growing further panics with a clear message instead of overflowing.
+const maxDense = 1 << 40
+
+// grown returns the first integer and the length of the range which the
+// storage of a dense set, holding the n integers from base, must grow to in
+// order to hold the integers from lo to hi. The storage is at least doubled in
+// the direction it grows, to amortize the cost of growing one integer at a
+// time, but it is kept within the limit and the bounds of int. It panics if
+// the range would hold more than maxDense integers, naming the set type name
+// in the message.
+func (l limit) grown(name string, base, n, lo, hi int) (int, int) {
+	first, last := lo, hi
+	if n > 0 {
+		first, last = min(lo, base), max(hi, base+n-1)
+	}
+	if uint(last)-uint(first) >= maxDense {
+		panic(fmt.Sprintf("intset: %s cannot grow to hold the integers from %d to %d", name, first, last))
+	}
+	if n == 0 {
+		return first, last - first + 1
+	}
+	lo, hi = first, last
+	if first < base {
+		first = min(first, subSat(base, n))
+	}
+	if last > base+n-1 {
+		last = max(last, addSat(base+n-1, n))
+	}
+	if l.on {
+		first, last = max(first, l.lo), min(last, l.hi)
+	}
+	if uint(last)-uint(first) >= maxDense {
+		first, last = lo, hi
+	}
+	return first, last - first + 1
+}
+
+// addSat returns i+n, or math.MaxInt if the sum overflows. N must not be
+// negative.
+func addSat(i, n int) int {
+	if i > math.MaxInt-n {
+		return math.MaxInt
+	}
+	return i + n
+}
+
+// subSat returns i-n, or math.MinInt if the difference overflows. N must not
+// be negative.
+func subSat(i, n int) int {
+	if i < math.MinInt+n {
+		return math.MinInt
+	}
+	return i - n
+}
+
+// bounds returns the smallest and the largest integer within the limit.
+func (l limit) bounds() (int, int) {
+	if !l.on {
+		return math.MinInt, math.MaxInt
+	}
+	return l.lo, l.hi
+}
+
+// ErrOutOfRange is matched by the errors returned by the checked methods of
+// the set types, such as TryAdd, when an integer is outside the limit of the
+// set. Use errors.As with an *OutOfRangeError to get the integer and the
+// limit.
+var ErrOutOfRange = errors.New("intset: integer out of range")
+
+// OutOfRangeError is the error returned by the checked methods of the set
+// types when an integer is outside the limit of the set.
+type OutOfRangeError[T Integer] struct {
+	Value    T   // the integer which is out of range
+	Min, Max int // the limit of the set, inclusive
+}
+
+func (e *OutOfRangeError[T]) Error() string {
+	return fmt.Sprintf("intset: %v is out of range [%d, %d]", e.Value, e.Min, e.Max)
+}
+
+// Is returns true if target is ErrOutOfRange.
+func (e *OutOfRangeError[T]) Is(target error) bool {
+	return target == ErrOutOfRange
+}
+
+// checkLimits returns an *OutOfRangeError for the first integer which is
+// outside the limit, or nil if there is none.
+func checkLimits[T Integer](l limit, ints []T) error {
+	for _, v := range ints {
+		if i, ok := toInt(v); !ok || !l.allows(i) {
+			lo, hi := l.bounds()
+			return &OutOfRangeError[T]{Value: v, Min: lo, Max: hi}
+		}
+	}
+	return nil
+}
+
+// checkLimit returns v as an int, or panics if it is outside the limit.
+func checkLimit[T Integer](l limit, name string, v T) int {
+	i, ok := toInt(v)
+	if !ok || !l.allows(i) {
+		panic(fmt.Sprintf("intset: %v is out of range for %s", v, name))
+	}
+	return i
+}
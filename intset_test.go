@@ -0,0 +1,236 @@
+package intset
+
+import (
+	"testing"
+
+	"github.com/knakk/specs"
+)
+
+var newSets = []func(max int) IntSet{
+	func(max int) IntSet { return NewAdaptiveSet(max) },
+	func(max int) IntSet { return NewArraySet(max) },
+	func(max int) IntSet { return NewBitSet(max) },
+	func(max int) IntSet { return NewBriggsSet(max) },
+	func(max int) IntSet { return NewHashSet(max) },
+	func(max int) IntSet { return NewIntervalSet(max) },
+	func(max int) IntSet { return NewRoaringSet(max) },
+	func(max int) IntSet { return NewSliceSet(max) },
+}
+
+func newIntSet(f func(int) IntSet, max int, ints ...int) IntSet {
+	set := f(max)
+	set.Insert(ints...)
+	return set
+}
+
+func TestIntSetInsertDelete(t *testing.T) {
+	specs := specs.New(t)
+
+	for _, f := range newSets {
+		set := newIntSet(f, 10, 1, 2, 5, 2)
+		specs.Expect(set.Contains(1, 2, 5), true)
+		specs.Expect(set.Size(), 3)
+		set.Delete(2, 5)
+		specs.Expect(set.Contains(2), false)
+		specs.Expect(set.Contains(5), false)
+		specs.Expect(set.Size(), 1)
+	}
+}
+
+func TestIntSetContainsOutsideUniverse(t *testing.T) {
+	specs := specs.New(t)
+
+	for _, f := range newSets {
+		set := newIntSet(f, 10, 1, 2)
+		specs.Expect(set.Contains(-1), false)
+		specs.Expect(set.Contains(99), false)
+	}
+}
+
+func TestIntSetMixed(t *testing.T) {
+	specs := specs.New(t)
+
+	for _, fa := range newSets {
+		for _, fb := range newSets {
+			setA := newIntSet(fa, 10, 1, 2, 4)
+			setB := newIntSet(fb, 20, 1, 2, 3, 15)
+
+			specs.Expect(setA.UnionSet(setB).Equal(newIntSet(fb, 20, 1, 2, 3, 4, 15)), true)
+			specs.Expect(setA.IntersectionSet(setB).Equal(newIntSet(fb, 20, 1, 2)), true)
+			specs.Expect(setA.DifferenceSet(setB).Equal(newIntSet(fb, 20, 4)), true)
+			specs.Expect(setB.DifferenceSet(setA).Equal(newIntSet(fb, 20, 3, 15)), true)
+			specs.Expect(setA.SymetricDifferenceSet(setB).Equal(newIntSet(fb, 20, 3, 4, 15)), true)
+			specs.Expect(setA.CloneSet().Equal(setA), true)
+			specs.Expect(setA.Equal(setB), false)
+			specs.Expect(setA.SubsetOf(setB), false)
+			specs.Expect(newIntSet(fa, 10, 1, 2).SubsetOf(setB), true)
+			specs.Expect(setB.SupersetOf(newIntSet(fa, 10, 1, 2)), true)
+		}
+	}
+}
+
+func TestIntSetNegative(t *testing.T) {
+	specs := specs.New(t)
+
+	type result struct {
+		v  int
+		ok bool
+	}
+	r := func(v int, ok bool) result { return result{v, ok} }
+
+	negSets := []func() IntSet{
+		func() IntSet { return NewBitSet(0) },
+		func() IntSet { return NewBriggsSetRange(-200, 200) },
+		func() IntSet { return NewHashSet(0) },
+		func() IntSet { return NewSliceSetRange(-200, 200) },
+	}
+	for _, fa := range negSets {
+		set := fa()
+		set.Insert(-130, -64, -1, 0, 5, 130)
+		specs.Expect(set.Contains(-130, -64, -1, 0, 5, 130), true)
+		specs.Expect(set.Contains(-2), false)
+		specs.Expect(set.Contains(-1000), false)
+		specs.Expect(set.Size(), 6)
+		specs.Expect(r(set.Min()), r(-130, true))
+		specs.Expect(r(set.Max()), r(130, true))
+		specs.Expect(r(set.NextAfter(-1000)), r(-130, true))
+		specs.Expect(r(set.NextAfter(-64)), r(-1, true))
+		specs.Expect(r(set.PrevBefore(0)), r(-1, true))
+		specs.Expect(r(set.PrevBefore(-130)), result{})
+		specs.Expect(set.String() != "", true)
+
+		set.Delete(-64)
+		specs.Expect(set.Contains(-64), false)
+		specs.Expect(set.Size(), 5)
+
+		for _, fb := range negSets {
+			other := fb()
+			other.Insert(-150, -130, 0, 7)
+			specs.Expect(set.UnionSet(other).Size(), 7)
+			specs.Expect(set.UnionSet(other).Contains(-150, -130, 7, 130), true)
+			specs.Expect(set.IntersectionSet(other).Equal(NewHashSet(0).Add(-130, 0)), true)
+			specs.Expect(set.DifferenceSet(other).Size(), 3)
+			specs.Expect(set.SymetricDifferenceSet(other).Size(), 5)
+			specs.Expect(set.IntersectionCount(other), 2)
+			specs.Expect(set.Intersects(other), true)
+			specs.Expect(set.CloneSet().Equal(set), true)
+		}
+	}
+}
+
+func testSetOf[T Integer](t *testing.T, newSets ...func(max int) Set[T]) {
+	specs := specs.New(t)
+
+	for _, fa := range newSets {
+		for _, fb := range newSets {
+			setA, setB := fa(100), fb(100)
+			setA.Insert(1, 2, 4, 99)
+			setB.Insert(1, 2, 3)
+
+			specs.Expect(setA.Contains(1, 2, 4, 99), true)
+			specs.Expect(setA.Contains(3), false)
+			specs.Expect(setA.Size(), 4)
+			specs.Expect(setA.UnionSet(setB).Size(), 5)
+			specs.Expect(setA.IntersectionSet(setB).Contains(1, 2), true)
+			specs.Expect(setA.IntersectionSet(setB).Size(), 2)
+			specs.Expect(setA.DifferenceSet(setB).Size(), 2)
+			specs.Expect(setA.SymetricDifferenceSet(setB).Size(), 3)
+			specs.Expect(setA.CloneSet().Equal(setA), true)
+			specs.Expect(setA.SupersetOf(setB), false)
+
+			setA.Delete(99)
+			specs.Expect(setA.Contains(99), false)
+			specs.Expect(setA.Size(), 3)
+		}
+	}
+}
+
+func TestSetOfIntegerTypes(t *testing.T) {
+	testSetOf(t,
+		func(max int) Set[uint16] { return NewBitSetOf[uint16](max) },
+		func(max int) Set[uint16] { return NewBriggsSetOf[uint16](max) },
+		func(max int) Set[uint16] { return NewHashSetOf[uint16](max) },
+		func(max int) Set[uint16] { return NewSliceSetOf[uint16](max) },
+	)
+	testSetOf(t,
+		func(max int) Set[uint32] { return NewBitSetOf[uint32](max) },
+		func(max int) Set[uint32] { return NewBriggsSetOf[uint32](max) },
+		func(max int) Set[uint32] { return NewHashSetOf[uint32](max) },
+		func(max int) Set[uint32] { return NewSliceSetOf[uint32](max) },
+	)
+	testSetOf(t,
+		func(max int) Set[int64] { return NewBitSetOf[int64](max) },
+		func(max int) Set[int64] { return NewBriggsSetOf[int64](max) },
+		func(max int) Set[int64] { return NewHashSetOf[int64](max) },
+		func(max int) Set[int64] { return NewSliceSetOf[int64](max) },
+	)
+}
+
+func TestSetOfOutOfRange(t *testing.T) {
+	specs := specs.New(t)
+
+	specs.Expect(NewBitSetOf[uint64](10).Contains(1<<64-1), false)
+	specs.Expect(NewSliceSetOf[int8](10).Contains(-1), false)
+	specs.Expect(NewBriggsSetOf[int64](10).Contains(-1), false)
+	specs.Expect(NewHashSetOf[int8](10).Add(-1).Contains(-1), true)
+}
+
+func TestIntSetNavigation(t *testing.T) {
+	specs := specs.New(t)
+
+	type result struct {
+		v  int
+		ok bool
+	}
+	r := func(v int, ok bool) result { return result{v, ok} }
+
+	for _, f := range newSets {
+		empty := f(200)
+		specs.Expect(r(empty.Min()), result{})
+		specs.Expect(r(empty.Max()), result{})
+		specs.Expect(r(empty.NextAfter(5)), result{})
+		specs.Expect(r(empty.PrevBefore(5)), result{})
+
+		set := newIntSet(f, 200, 3, 63, 64, 130, 200)
+		specs.Expect(r(set.Min()), r(3, true))
+		specs.Expect(r(set.Max()), r(200, true))
+
+		specs.Expect(r(set.NextAfter(-5)), r(3, true))
+		specs.Expect(r(set.NextAfter(3)), r(63, true))
+		specs.Expect(r(set.NextAfter(63)), r(64, true))
+		specs.Expect(r(set.NextAfter(65)), r(130, true))
+		specs.Expect(r(set.NextAfter(200)), result{})
+		specs.Expect(r(set.NextAfter(1000)), result{})
+
+		specs.Expect(r(set.PrevBefore(3)), result{})
+		specs.Expect(r(set.PrevBefore(-5)), result{})
+		specs.Expect(r(set.PrevBefore(64)), r(63, true))
+		specs.Expect(r(set.PrevBefore(130)), r(64, true))
+		specs.Expect(r(set.PrevBefore(200)), r(130, true))
+		specs.Expect(r(set.PrevBefore(1000)), r(200, true))
+	}
+}
+
+func TestIntSetCounts(t *testing.T) {
+	specs := specs.New(t)
+
+	for _, fa := range newSets {
+		for _, fb := range newSets {
+			setA := newIntSet(fa, 200, 1, 2, 4, 64, 130)
+			setB := newIntSet(fb, 200, 1, 2, 3, 130, 200)
+			empty := fb(200)
+
+			specs.Expect(setA.IntersectionCount(setB), 3)
+			specs.Expect(setA.UnionCount(setB), 7)
+			specs.Expect(setA.DifferenceCount(setB), 2)
+			specs.Expect(setB.DifferenceCount(setA), 2)
+			specs.Expect(setA.SymmetricDifferenceCount(setB), 4)
+			specs.Expect(setA.Intersects(setB), true)
+
+			specs.Expect(setA.IntersectionCount(empty), 0)
+			specs.Expect(setA.UnionCount(empty), 5)
+			specs.Expect(setA.Intersects(empty), false)
+			specs.Expect(setA.Intersects(newIntSet(fb, 200, 3, 200)), false)
+		}
+	}
+}
@@ -0,0 +1,741 @@
+// Package intsettest implements a conformance suite for implementations of
+// intset.Set, so that every set type, including those outside the intset
+// package, is verified the same way.
+//
+// The suite checks each method against a simple model of the set, and the
+// algebraic laws of the set operations: commutativity, associativity,
+// distributivity, De Morgan's laws, idempotence, the consistency of SubsetOf,
+// SupersetOf and Equal, and the independence of clones. The sets are built
+// from a factory, with random integers from 0 to Max, and with sparse integers
+// and runs drawn from a wide range, which includes the negative integers and
+// the bounds of the integer type:
+//
+//	func TestConformance(t *testing.T) {
+//		intsettest.Run(t, func(max int) intset.Set[int] {
+//			return mypkg.NewSet(max)
+//		})
+//	}
+//
+// The range operations, which the Set interface does not include, are checked
+// by RunRanges.
+package intsettest
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/knakk/intset"
+)
+
+// Max is the largest integer in the sets built by the suite. It fits in all
+// the integer types.
+const Max = 100
+
+// Wide bounds the wide range of Run and RunMixed: the integers from -Wide to
+// Wide, or the bounds of the integer type if they are closer, so that the
+// dense sets stay small.
+const Wide = 1 << 16
+
+// rounds is the number of random sets, or tuples of sets, each test is run
+// with.
+const rounds = 50
+
+// Factory returns a new empty set, which can hold at least the integers from 0
+// to max, and grows to hold the other integers inserted into it.
+type Factory[T intset.Integer] func(max int) intset.Set[T]
+
+// Run runs the suite against the sets returned by newSet, as subtests of t.
+func Run[T intset.Integer](t *testing.T, newSet Factory[T]) {
+	RunMixed(t, newSet, newSet)
+}
+
+// RunMixed runs the suite with operands from two factories, as subtests of t.
+// The first operand of each operation is built by newA, and the other operands
+// by newB, so that sets of different types are verified to combine correctly.
+func RunMixed[T intset.Integer](t *testing.T, newA, newB Factory[T]) {
+	lo, hi := wideBounds[T]()
+	RunWithin(t, lo, hi, newA, newB)
+}
+
+// RunWithin runs the suite like RunMixed, with the wide range of integers from
+// lo to hi, for the sets which cannot hold the default one.
+func RunWithin[T intset.Integer](t *testing.T, lo, hi T, newA, newB Factory[T]) {
+	s := newSuite(lo, hi, newA, newB)
+	t.Run("Basics", s.testBasics)
+	t.Run("Navigation", s.testNavigation)
+	t.Run("Algebra", s.testAlgebra)
+	t.Run("Wide", s.testWide)
+	t.Run("WideAlgebra", s.testWideAlgebra)
+	t.Run("Counts", s.testCounts)
+	t.Run("Commutativity", s.testCommutativity)
+	t.Run("Associativity", s.testAssociativity)
+	t.Run("Distributivity", s.testDistributivity)
+	t.Run("DeMorgan", s.testDeMorgan)
+	t.Run("Idempotence", s.testIdempotence)
+	t.Run("Subset", s.testSubset)
+	t.Run("Clone", s.testClone)
+}
+
+// model is the reference implementation the sets are checked against.
+type model[T intset.Integer] map[T]bool
+
+func (m model[T]) union(o model[T]) model[T] {
+	result := model[T]{}
+	for v := range m {
+		result[v] = true
+	}
+	for v := range o {
+		result[v] = true
+	}
+	return result
+}
+
+func (m model[T]) intersection(o model[T]) model[T] {
+	result := model[T]{}
+	for v := range m {
+		if o[v] {
+			result[v] = true
+		}
+	}
+	return result
+}
+
+func (m model[T]) difference(o model[T]) model[T] {
+	result := model[T]{}
+	for v := range m {
+		if !o[v] {
+			result[v] = true
+		}
+	}
+	return result
+}
+
+func (m model[T]) symmetricDifference(o model[T]) model[T] {
+	return m.difference(o).union(o.difference(m))
+}
+
+func (m model[T]) sorted() []T {
+	ints := make([]T, 0, len(m))
+	for v := range m {
+		ints = append(ints, v)
+	}
+	slices.Sort(ints)
+	return ints
+}
+
+// RangeSet is a set with the range operations checked by RunRanges, which
+// return the set itself.
+type RangeSet[T intset.Integer, S any] interface {
+	intset.Set[T]
+	AddRange(lo, hi T) S
+	RemoveRange(lo, hi T) S
+	FlipRange(lo, hi T) S
+}
+
+// RunRanges checks AddRange, RemoveRange and FlipRange of the sets returned by
+// newSet against the model, as subtests of t, with ranges of the integers from
+// lo to hi.
+func RunRanges[T intset.Integer, S RangeSet[T, S]](t *testing.T, lo, hi T, newSet func(max int) S) {
+	f := func(max int) intset.Set[T] { return newSet(max) }
+	s := newSuite(lo, hi, f, f)
+	t.Run("Ranges", func(t *testing.T) {
+		testRanges(t, s, newSet)
+	})
+}
+
+type suite[T intset.Integer] struct {
+	newA, newB Factory[T]
+	rand       *rand.Rand
+
+	// lo and hi are the bounds of the wide range, and span is the number of
+	// integers in it minus one, which fits in an uint64 for all the integer
+	// types.
+	lo, hi T
+	span   uint64
+}
+
+func newSuite[T intset.Integer](lo, hi T, newA, newB Factory[T]) *suite[T] {
+	if lo > 0 || hi < Max {
+		panic("intsettest: the wide range must include the integers from 0 to Max")
+	}
+	return &suite[T]{
+		newA: newA,
+		newB: newB,
+		rand: rand.New(rand.NewSource(1)),
+		lo:   lo,
+		hi:   hi,
+		span: uint64(hi) - uint64(lo),
+	}
+}
+
+// wideBounds returns the default wide range for T: the integers from -Wide to
+// Wide, clipped to the bounds of T.
+func wideBounds[T intset.Integer]() (T, T) {
+	// find the largest power of two in T, and fill the bits below it
+	top := T(1)
+	for top<<1 > top {
+		top <<= 1
+	}
+	lo, hi := T(0), top|(top-1)
+	wide := int64(Wide)
+	if lo-1 < lo {
+		// T is signed
+		lo = -hi - 1
+		if int64(lo) < -wide {
+			lo = T(-wide)
+		}
+	}
+	if uint64(hi) > uint64(wide) {
+		hi = T(wide)
+	}
+	return lo, hi
+}
+
+// randomModel returns a model with random integers from 0 to Max. The density
+// varies between the models, from empty to full, and some of them hold runs
+// of consecutive integers.
+func (s *suite[T]) randomModel() model[T] {
+	m := model[T]{}
+	switch s.rand.Intn(6) {
+	case 0:
+	case 1:
+		for v := 0; v <= Max; v++ {
+			m[T(v)] = true
+		}
+	case 2:
+		lo := s.rand.Intn(Max + 1)
+		for v := lo; v <= min(Max, lo+s.rand.Intn(30)); v++ {
+			m[T(v)] = true
+		}
+	default:
+		p := []float64{0.05, 0.3, 0.8}[s.rand.Intn(3)]
+		for v := 0; v <= Max; v++ {
+			if s.rand.Float64() < p {
+				m[T(v)] = true
+			}
+		}
+	}
+	return m
+}
+
+// at returns the integer at the offset o from the start of the wide range.
+func (s *suite[T]) at(o uint64) T {
+	return T(uint64(s.lo) + o)
+}
+
+// offset returns a random offset in the wide range.
+func (s *suite[T]) offset() uint64 {
+	if s.span == math.MaxUint64 {
+		return s.rand.Uint64()
+	}
+	return s.rand.Uint64() % (s.span + 1)
+}
+
+// run adds the integers from the offset o to o+n, clipped to the wide range, to
+// m.
+func (s *suite[T]) run(m model[T], o, n uint64) {
+	n = min(n, s.span-o)
+	for i := uint64(0); i <= n; i++ {
+		m[s.at(o+i)] = true
+	}
+}
+
+// wideModel returns a model with integers from the wide range: sparse
+// integers spread over the whole range, runs of consecutive integers at its
+// bounds, around zero or at random places, or a mix of them.
+func (s *suite[T]) wideModel() model[T] {
+	m := model[T]{}
+	for parts := s.rand.Intn(5); parts > 0; parts-- {
+		n := uint64(s.rand.Intn(20))
+		switch s.rand.Intn(5) {
+		case 0:
+			for ; n > 0; n-- {
+				m[s.at(s.offset())] = true
+			}
+		case 1:
+			s.run(m, 0, n)
+		case 2:
+			s.run(m, s.span-min(n, s.span), n)
+		case 3:
+			// zero is in the wide range, since Max is
+			z := -uint64(s.lo)
+			s.run(m, z-min(n/2, z), n)
+		default:
+			s.run(m, s.offset(), n)
+		}
+	}
+	return m
+}
+
+// probes returns the integers to check the navigation of a set holding the
+// integers of m with: the bounds of the wide range, and the integers of m and
+// their neighbours.
+func (s *suite[T]) probes(m model[T]) []T {
+	probes := []T{s.lo, s.hi}
+	for v := range m {
+		probes = append(probes, v-1, v, v+1)
+	}
+	return probes
+}
+
+// build returns a new set from newSet holding the integers of m, in random
+// order.
+func build[T intset.Integer](newSet Factory[T], m model[T], r *rand.Rand) intset.Set[T] {
+	ints := m.sorted()
+	r.Shuffle(len(ints), func(i, j int) { ints[i], ints[j] = ints[j], ints[i] })
+	set := newSet(Max)
+	set.Insert(ints...)
+	return set
+}
+
+// operands returns n models from random, and sets built from them: the first
+// one by newA and the others by newB.
+func (s *suite[T]) operands(n int, random func() model[T]) ([]model[T], []intset.Set[T]) {
+	models := make([]model[T], n)
+	sets := make([]intset.Set[T], n)
+	for k := range models {
+		models[k] = random()
+		newSet := s.newB
+		if k == 0 {
+			newSet = s.newA
+		}
+		sets[k] = build(newSet, models[k], s.rand)
+	}
+	return models, sets
+}
+
+// check reports an error if set does not hold exactly the integers of m.
+func check[T intset.Integer](t *testing.T, what string, set intset.Set[T], m model[T]) {
+	t.Helper()
+	want := m.sorted()
+	if n := set.Size(); n != len(want) {
+		t.Errorf("%s: Size() = %d, want %d", what, n, len(want))
+	}
+	all := set.All()
+	slices.Sort(all)
+	if !slices.Equal(all, want) {
+		t.Errorf("%s: All() = %v, want %v", what, all, want)
+	}
+	var values []T
+	for v := range set.Values() {
+		values = append(values, v)
+	}
+	slices.Sort(values)
+	if !slices.Equal(values, want) {
+		t.Errorf("%s: Values() = %v, want %v", what, values, want)
+	}
+	for v := 0; v <= Max; v++ {
+		if got := set.Contains(T(v)); got != m[T(v)] {
+			t.Errorf("%s: Contains(%d) = %v, want %v", what, v, got, m[T(v)])
+		}
+	}
+	// the neighbours of the integers wrap around at the bounds of T
+	for _, v := range want {
+		for _, n := range []T{v - 1, v + 1} {
+			if got := set.Contains(n); got != m[n] {
+				t.Errorf("%s: Contains(%d) = %v, want %v", what, n, got, m[n])
+			}
+		}
+	}
+}
+
+// checkNavigation reports an error if Min, Max, NextAfter or PrevBefore of set,
+// at the integers of probes, do not agree with m.
+func checkNavigation[T intset.Integer](t *testing.T, set intset.Set[T], m model[T], probes []T) {
+	t.Helper()
+	ints := m.sorted()
+	lo, ok := set.Min()
+	if ok != (len(ints) > 0) || ok && lo != ints[0] {
+		t.Errorf("Min() of %v = %v, %v", ints, lo, ok)
+	}
+	hi, ok := set.Max()
+	if ok != (len(ints) > 0) || ok && hi != ints[len(ints)-1] {
+		t.Errorf("Max() of %v = %v, %v", ints, hi, ok)
+	}
+	for _, v := range probes {
+		// j is the number of integers less than v, and k of those up to v
+		j, found := slices.BinarySearch(ints, v)
+		k := j
+		if found {
+			k++
+		}
+		next, ok := set.NextAfter(v)
+		if want := k < len(ints); ok != want || ok && next != ints[k] {
+			t.Errorf("NextAfter(%d) of %v = %v, %v", v, ints, next, ok)
+		}
+		prev, ok := set.PrevBefore(v)
+		if want := j > 0; ok != want || ok && prev != ints[j-1] {
+			t.Errorf("PrevBefore(%d) of %v = %v, %v", v, ints, prev, ok)
+		}
+	}
+}
+
+// expectEqual reports an error if a and b are not equal according to Equal.
+func expectEqual[T intset.Integer](t *testing.T, law string, a, b intset.Set[T]) {
+	t.Helper()
+	if !a.Equal(b) || !b.Equal(a) {
+		t.Errorf("%s does not hold: %v != %v", law, a, b)
+	}
+}
+
+// universe returns a set from newSet with all the integers from 0 to Max.
+func universe[T intset.Integer](newSet Factory[T]) intset.Set[T] {
+	set := newSet(Max)
+	for v := 0; v <= Max; v++ {
+		set.Insert(T(v))
+	}
+	return set
+}
+
+func (s *suite[T]) testBasics(t *testing.T) {
+	for k := 0; k < rounds; k++ {
+		m := s.randomModel()
+		set := build(s.newA, m, s.rand)
+		check(t, "built set", set, m)
+
+		if !set.Contains() {
+			t.Errorf("Contains() with no integers = false, want true")
+		}
+		if ints := m.sorted(); len(ints) > 0 && !set.Contains(ints...) {
+			t.Errorf("Contains(%v) = false, want true", ints)
+		}
+
+		// inserting integers twice and deleting absent ones has no effect
+		set.Insert(m.sorted()...)
+		for v := 0; v <= Max; v++ {
+			if !m[T(v)] {
+				set.Delete(T(v))
+			}
+		}
+		check(t, "after redundant Insert and Delete", set, m)
+
+		for i := 0; i < 20; i++ {
+			v := T(s.rand.Intn(Max + 1))
+			if s.rand.Intn(2) == 0 {
+				set.Insert(v)
+				m[v] = true
+			} else {
+				set.Delete(v)
+				delete(m, v)
+			}
+		}
+		check(t, "after Insert and Delete", set, m)
+
+		set.Delete(m.sorted()...)
+		check(t, "after deleting all integers", set, model[T]{})
+
+		for range set.Values() {
+			t.Errorf("Values() of an empty set yields integers")
+			break
+		}
+	}
+}
+
+func (s *suite[T]) testNavigation(t *testing.T) {
+	for k := 0; k < rounds; k++ {
+		m := s.randomModel()
+		set := build(s.newA, m, s.rand)
+		var probes []T
+		for v := 0; v <= Max+1; v++ {
+			probes = append(probes, T(v))
+		}
+		checkNavigation(t, set, m, probes)
+
+		// stopping an iteration early must not panic
+		for range set.Values() {
+			break
+		}
+	}
+}
+
+func (s *suite[T]) testAlgebra(t *testing.T) {
+	for k := 0; k < rounds; k++ {
+		models, sets := s.operands(2, s.randomModel)
+		ma, mb := models[0], models[1]
+		a, b := sets[0], sets[1]
+
+		check(t, "UnionSet", a.UnionSet(b), ma.union(mb))
+		check(t, "IntersectionSet", a.IntersectionSet(b), ma.intersection(mb))
+		check(t, "DifferenceSet", a.DifferenceSet(b), ma.difference(mb))
+		check(t, "SymetricDifferenceSet", a.SymetricDifferenceSet(b), ma.symmetricDifference(mb))
+
+		// the operands are left unchanged
+		check(t, "first operand", a, ma)
+		check(t, "second operand", b, mb)
+
+		equal := len(ma.symmetricDifference(mb)) == 0
+		if got := a.Equal(b); got != equal {
+			t.Errorf("Equal(%v, %v) = %v, want %v", a, b, got, equal)
+		}
+	}
+}
+
+func (s *suite[T]) testCounts(t *testing.T) {
+	for k := 0; k < rounds; k++ {
+		models, sets := s.operands(2, s.randomModel)
+		ma, mb := models[0], models[1]
+		a, b := sets[0], sets[1]
+
+		counts := []struct {
+			name      string
+			got, want int
+		}{
+			{"IntersectionCount", a.IntersectionCount(b), len(ma.intersection(mb))},
+			{"UnionCount", a.UnionCount(b), len(ma.union(mb))},
+			{"DifferenceCount", a.DifferenceCount(b), len(ma.difference(mb))},
+			{"SymmetricDifferenceCount", a.SymmetricDifferenceCount(b), len(ma.symmetricDifference(mb))},
+		}
+		for _, c := range counts {
+			if c.got != c.want {
+				t.Errorf("%s(%v, %v) = %d, want %d", c.name, a, b, c.got, c.want)
+			}
+		}
+		if got, want := a.Intersects(b), len(ma.intersection(mb)) > 0; got != want {
+			t.Errorf("Intersects(%v, %v) = %v, want %v", a, b, got, want)
+		}
+	}
+}
+
+func (s *suite[T]) testCommutativity(t *testing.T) {
+	for k := 0; k < rounds; k++ {
+		_, sets := s.operands(2, s.randomModel)
+		a, b := sets[0], sets[1]
+
+		expectEqual(t, "A ∪ B = B ∪ A", a.UnionSet(b), b.UnionSet(a))
+		expectEqual(t, "A ∩ B = B ∩ A", a.IntersectionSet(b), b.IntersectionSet(a))
+		expectEqual(t, "A Δ B = B Δ A", a.SymetricDifferenceSet(b), b.SymetricDifferenceSet(a))
+		if got, want := a.IntersectionCount(b), b.IntersectionCount(a); got != want {
+			t.Errorf("IntersectionCount is not commutative: %d != %d", got, want)
+		}
+	}
+}
+
+func (s *suite[T]) testAssociativity(t *testing.T) {
+	for k := 0; k < rounds; k++ {
+		_, sets := s.operands(3, s.randomModel)
+		a, b, c := sets[0], sets[1], sets[2]
+
+		expectEqual(t, "(A ∪ B) ∪ C = A ∪ (B ∪ C)", a.UnionSet(b).UnionSet(c), a.UnionSet(b.UnionSet(c)))
+		expectEqual(t, "(A ∩ B) ∩ C = A ∩ (B ∩ C)", a.IntersectionSet(b).IntersectionSet(c), a.IntersectionSet(b.IntersectionSet(c)))
+		expectEqual(t, "(A Δ B) Δ C = A Δ (B Δ C)", a.SymetricDifferenceSet(b).SymetricDifferenceSet(c), a.SymetricDifferenceSet(b.SymetricDifferenceSet(c)))
+	}
+}
+
+func (s *suite[T]) testDistributivity(t *testing.T) {
+	for k := 0; k < rounds; k++ {
+		_, sets := s.operands(3, s.randomModel)
+		a, b, c := sets[0], sets[1], sets[2]
+
+		expectEqual(t, "A ∩ (B ∪ C) = (A ∩ B) ∪ (A ∩ C)", a.IntersectionSet(b.UnionSet(c)), a.IntersectionSet(b).UnionSet(a.IntersectionSet(c)))
+		expectEqual(t, "A ∪ (B ∩ C) = (A ∪ B) ∩ (A ∪ C)", a.UnionSet(b.IntersectionSet(c)), a.UnionSet(b).IntersectionSet(a.UnionSet(c)))
+		expectEqual(t, "A ∪ (A ∩ B) = A", a.UnionSet(a.IntersectionSet(b)), a)
+		expectEqual(t, "A ∩ (A ∪ B) = A", a.IntersectionSet(a.UnionSet(b)), a)
+	}
+}
+
+func (s *suite[T]) testDeMorgan(t *testing.T) {
+	for k := 0; k < rounds; k++ {
+		_, sets := s.operands(2, s.randomModel)
+		a, b := sets[0], sets[1]
+		u := universe(s.newA)
+
+		expectEqual(t, "U \\ (A ∪ B) = (U \\ A) ∩ (U \\ B)", u.DifferenceSet(a.UnionSet(b)), u.DifferenceSet(a).IntersectionSet(u.DifferenceSet(b)))
+		expectEqual(t, "U \\ (A ∩ B) = (U \\ A) ∪ (U \\ B)", u.DifferenceSet(a.IntersectionSet(b)), u.DifferenceSet(a).UnionSet(u.DifferenceSet(b)))
+		expectEqual(t, "A \\ B = A ∩ (U \\ B)", a.DifferenceSet(b), a.IntersectionSet(u.DifferenceSet(b)))
+	}
+}
+
+func (s *suite[T]) testIdempotence(t *testing.T) {
+	for k := 0; k < rounds; k++ {
+		m := s.randomModel()
+		a := build(s.newA, m, s.rand)
+		empty := s.newA(Max)
+
+		expectEqual(t, "A ∪ A = A", a.UnionSet(a), a)
+		expectEqual(t, "A ∩ A = A", a.IntersectionSet(a), a)
+		expectEqual(t, "A \\ A = ∅", a.DifferenceSet(a), empty)
+		expectEqual(t, "A Δ A = ∅", a.SymetricDifferenceSet(a), empty)
+		expectEqual(t, "A ∪ ∅ = A", a.UnionSet(empty), a)
+		expectEqual(t, "A ∩ ∅ = ∅", a.IntersectionSet(empty), empty)
+		check(t, "A after operations with itself", a, m)
+	}
+}
+
+func (s *suite[T]) testSubset(t *testing.T) {
+	for k := 0; k < rounds; k++ {
+		models, sets := s.operands(2, s.randomModel)
+		ma, mb := models[0], models[1]
+		a, b := sets[0], sets[1]
+
+		subset := len(ma.difference(mb)) == 0
+		if got := a.SubsetOf(b); got != subset {
+			t.Errorf("SubsetOf(%v, %v) = %v, want %v", a, b, got, subset)
+		}
+		if got := b.SupersetOf(a); got != subset {
+			t.Errorf("SupersetOf(%v, %v) = %v, want %v", b, a, got, subset)
+		}
+		if got, want := a.Equal(b), a.SubsetOf(b) && b.SubsetOf(a); got != want {
+			t.Errorf("Equal(%v, %v) = %v, but SubsetOf both ways is %v", a, b, got, want)
+		}
+		if !a.SubsetOf(a) || !a.SupersetOf(a) {
+			t.Errorf("%v is not a subset and a superset of itself", a)
+		}
+		if !a.SubsetOf(a.UnionSet(b)) || !a.IntersectionSet(b).SubsetOf(a) {
+			t.Errorf("A ⊆ A ∪ B or A ∩ B ⊆ A does not hold for %v and %v", a, b)
+		}
+		if !s.newA(Max).SubsetOf(a) {
+			t.Errorf("∅ ⊆ A does not hold for %v", a)
+		}
+	}
+}
+
+func (s *suite[T]) testClone(t *testing.T) {
+	for k := 0; k < rounds; k++ {
+		m := s.randomModel()
+		a := build(s.newA, m, s.rand)
+		c := a.CloneSet()
+		check(t, "clone", c, m)
+		expectEqual(t, "clone(A) = A", c, a)
+
+		// modifying the clone leaves the original unchanged, and vice versa
+		v := T(s.rand.Intn(Max + 1))
+		if m[v] {
+			c.Delete(v)
+			if !a.Contains(v) {
+				t.Errorf("deleting %v from a clone deleted it from the original", v)
+			}
+			a.Delete(v)
+			a.Insert(v)
+			if c.Contains(v) {
+				t.Errorf("inserting %v into the original inserted it into a clone", v)
+			}
+		} else {
+			c.Insert(v)
+			if a.Contains(v) {
+				t.Errorf("inserting %v into a clone inserted it into the original", v)
+			}
+			a.Insert(v)
+			a.Delete(v)
+			if !c.Contains(v) {
+				t.Errorf("deleting %v from the original deleted it from a clone", v)
+			}
+		}
+		check(t, "original after modifying the clone", a, m)
+	}
+}
+
+func (s *suite[T]) testWide(t *testing.T) {
+	for k := 0; k < rounds; k++ {
+		m := s.wideModel()
+		set := build(s.newA, m, s.rand)
+		check(t, "wide set", set, m)
+		checkNavigation(t, set, m, s.probes(m))
+
+		for i := 0; i < 20; i++ {
+			v := s.at(s.offset())
+			if s.rand.Intn(2) == 0 {
+				set.Insert(v)
+				m[v] = true
+			} else {
+				set.Delete(v)
+				delete(m, v)
+			}
+		}
+		check(t, "wide set after Insert and Delete", set, m)
+		checkNavigation(t, set, m, s.probes(m))
+	}
+}
+
+func (s *suite[T]) testWideAlgebra(t *testing.T) {
+	for k := 0; k < rounds; k++ {
+		models, sets := s.operands(2, s.wideModel)
+		ma, mb := models[0], models[1]
+		a, b := sets[0], sets[1]
+
+		check(t, "wide UnionSet", a.UnionSet(b), ma.union(mb))
+		check(t, "wide IntersectionSet", a.IntersectionSet(b), ma.intersection(mb))
+		check(t, "wide DifferenceSet", a.DifferenceSet(b), ma.difference(mb))
+		check(t, "wide SymetricDifferenceSet", a.SymetricDifferenceSet(b), ma.symmetricDifference(mb))
+		check(t, "first wide operand", a, ma)
+		check(t, "second wide operand", b, mb)
+
+		if got, want := a.UnionCount(b), len(ma.union(mb)); got != want {
+			t.Errorf("UnionCount(%v, %v) = %d, want %d", a, b, got, want)
+		}
+		if got, want := a.IntersectionCount(b), len(ma.intersection(mb)); got != want {
+			t.Errorf("IntersectionCount(%v, %v) = %d, want %d", a, b, got, want)
+		}
+		if got, want := a.SubsetOf(b), len(ma.difference(mb)) == 0; got != want {
+			t.Errorf("SubsetOf(%v, %v) = %v, want %v", a, b, got, want)
+		}
+	}
+}
+
+// testRanges applies random range operations to sets from newSet, and checks
+// them against the model. The ranges are short, and placed at the bounds of
+// the wide range, around zero, around the integers of the set or at random
+// places; some of them are empty.
+func testRanges[T intset.Integer, S RangeSet[T, S]](t *testing.T, s *suite[T], newSet func(max int) S) {
+	for k := 0; k < rounds; k++ {
+		m := s.wideModel()
+		set := newSet(Max)
+		set.Insert(m.sorted()...)
+		for i := 0; i < 10; i++ {
+			n := uint64(s.rand.Intn(2 * Max))
+			var o uint64
+			switch s.rand.Intn(4) {
+			case 0:
+				o = 0
+			case 1:
+				o = s.span - min(n, s.span)
+			case 2:
+				z := -uint64(s.lo)
+				o = z - min(n/2, z)
+			default:
+				o = s.offset()
+			}
+			n = min(n, s.span-o)
+			lo, hi := s.at(o), s.at(o+n)
+			if s.rand.Intn(8) == 0 {
+				lo, hi = hi, lo
+			}
+
+			var what string
+			var update func(v T)
+			switch s.rand.Intn(3) {
+			case 0:
+				what = "AddRange"
+				set.AddRange(lo, hi)
+				update = func(v T) { m[v] = true }
+			case 1:
+				what = "RemoveRange"
+				set.RemoveRange(lo, hi)
+				update = func(v T) { delete(m, v) }
+			default:
+				what = "FlipRange"
+				set.FlipRange(lo, hi)
+				update = func(v T) {
+					if m[v] {
+						delete(m, v)
+					} else {
+						m[v] = true
+					}
+				}
+			}
+			// the loop stops at hi, since hi+1 overflows at the bounds of T
+			if lo <= hi {
+				for v := lo; ; v++ {
+					update(v)
+					if v == hi {
+						break
+					}
+				}
+			}
+			check(t, fmt.Sprintf("%s(%d, %d)", what, lo, hi), set, m)
+			checkNavigation(t, set, m, []T{s.lo, s.hi, lo - 1, lo, lo + 1, hi - 1, hi, hi + 1})
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package intsettest_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/knakk/intset"
+	"github.com/knakk/intset/intsettest"
+)
+
+var factories = []intsettest.Factory[int]{
+	func(max int) intset.IntSet { return intset.NewAdaptiveSet(max) },
+	func(max int) intset.IntSet { return intset.NewArraySet(max) },
+	func(max int) intset.IntSet { return intset.NewBitSet(max) },
+	func(max int) intset.IntSet { return intset.NewBriggsSet(max) },
+	func(max int) intset.IntSet { return intset.NewHashSet(max) },
+	func(max int) intset.IntSet { return intset.NewIntervalSet(max) },
+	func(max int) intset.IntSet { return intset.NewRoaringSet(max) },
+	func(max int) intset.IntSet { return intset.NewSliceSet(max) },
+	func(max int) intset.IntSet { return intset.Synchronized[int](intset.NewHashSet(max)) },
+}
+
+// name returns the name of the set type returned by f.
+func name(f intsettest.Factory[int]) string {
+	return fmt.Sprintf("%T", f(0))
+}
+
+// within returns the wide range for the sets returned by f: a RoaringSet holds
+// only the integers from 0 to 2^32-1, the dense sets are kept small, and the
+// other sets hold all the ints.
+func within(f intsettest.Factory[int]) (int, int) {
+	switch f(0).(type) {
+	case *intset.RoaringSet:
+		return 0, 1<<32 - 1
+	case *intset.BitSet[int], *intset.BriggsSet[int], *intset.SliceSet[int]:
+		return -intsettest.Wide, intsettest.Wide
+	}
+	return math.MinInt, math.MaxInt
+}
+
+func TestConformance(t *testing.T) {
+	for _, f := range factories {
+		t.Run(name(f), func(t *testing.T) {
+			lo, hi := within(f)
+			intsettest.RunWithin(t, lo, hi, f, f)
+		})
+	}
+}
+
+func TestConformanceMixed(t *testing.T) {
+	for _, a := range factories {
+		for _, b := range factories {
+			if name(a) == name(b) {
+				continue
+			}
+			t.Run(name(a)+"/"+name(b), func(t *testing.T) {
+				loA, hiA := within(a)
+				loB, hiB := within(b)
+				intsettest.RunWithin(t, max(loA, loB), min(hiA, hiB), a, b)
+			})
+		}
+	}
+}
+
+func TestConformanceOf(t *testing.T) {
+	intsettest.Run(t, func(max int) intset.Set[uint8] { return intset.NewBitSetOf[uint8](max) })
+	intsettest.Run(t, func(max int) intset.Set[int8] { return intset.NewSliceSetOf[int8](max) })
+	intsettest.Run(t, func(max int) intset.Set[uint16] { return intset.NewIntervalSetOf[uint16](max) })
+}
+
+func TestRanges(t *testing.T) {
+	intsettest.RunRanges(t, math.MinInt, math.MaxInt, intset.NewAdaptiveSet)
+	intsettest.RunRanges(t, math.MinInt, math.MaxInt, intset.NewArraySet)
+	intsettest.RunRanges(t, -intsettest.Wide, intsettest.Wide, intset.NewBitSet)
+	intsettest.RunRanges(t, -intsettest.Wide, intsettest.Wide, intset.NewBriggsSet)
+	intsettest.RunRanges(t, math.MinInt, math.MaxInt, intset.NewHashSet)
+	intsettest.RunRanges(t, math.MinInt, math.MaxInt, intset.NewIntervalSet)
+	intsettest.RunRanges(t, 0, 1<<32-1, intset.NewRoaringSet)
+	intsettest.RunRanges(t, -intsettest.Wide, intsettest.Wide, intset.NewSliceSet)
+}
+
+func TestRangesOf(t *testing.T) {
+	intsettest.RunRanges(t, 0, math.MaxUint8, intset.NewBitSetOf[uint8])
+	intsettest.RunRanges(t, math.MinInt8, math.MaxInt8, intset.NewSliceSetOf[int8])
+	intsettest.RunRanges(t, 0, math.MaxUint16, intset.NewIntervalSetOf[uint16])
+	intsettest.RunRanges(t, math.MinInt16, math.MaxInt16, intset.NewArraySetOf[int16])
+	intsettest.RunRanges(t, 0, math.MaxUint64, intset.NewHashSetOf[uint64])
+}
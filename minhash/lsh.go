@@ -0,0 +1,65 @@
+package minhash
+
+import "slices"
+
+// Index is a banded locality-sensitive hashing index of signatures. Each
+// signature is split in bands of rows values, and two signatures are
+// candidates for similarity if all the values of at least one band are equal.
+//
+// With b bands of r rows, sets with Jaccard index s are candidates with
+// probability 1-(1-s^r)^b, an S-curve with its threshold near (1/b)^(1/r).
+type Index struct {
+	bands, rows int
+	buckets     []map[uint64][]int // the ids in each bucket of each band
+}
+
+// NewIndex is the constructor for an Index of signatures of at least
+// bands*rows values.
+func NewIndex(bands, rows int) *Index {
+	if bands <= 0 || rows <= 0 {
+		panic("minhash: bands and rows must be positive")
+	}
+	idx := &Index{bands: bands, rows: rows, buckets: make([]map[uint64][]int, bands)}
+	for b := range idx.buckets {
+		idx.buckets[b] = make(map[uint64][]int)
+	}
+	return idx
+}
+
+// Insert adds the signature of the set with the given id to the index. It
+// panics if the signature is too short.
+func (idx *Index) Insert(id int, sig Signature) {
+	idx.check(sig)
+	for b, bucket := range idx.buckets {
+		key := idx.key(b, sig)
+		bucket[key] = append(bucket[key], id)
+	}
+}
+
+// Query returns the ids of the candidates for similarity with the signature,
+// in ascending order and without duplicates. It panics if the signature is too
+// short.
+func (idx *Index) Query(sig Signature) []int {
+	idx.check(sig)
+	var ids []int
+	for b, bucket := range idx.buckets {
+		ids = append(ids, bucket[idx.key(b, sig)]...)
+	}
+	slices.Sort(ids)
+	return slices.Compact(ids)
+}
+
+func (idx *Index) check(sig Signature) {
+	if len(sig) < idx.bands*idx.rows {
+		panic("minhash: signature too short for index")
+	}
+}
+
+// key returns the hash of band b of the signature.
+func (idx *Index) key(b int, sig Signature) uint64 {
+	key := uint64(b)
+	for _, x := range sig[b*idx.rows : (b+1)*idx.rows] {
+		key = mix(key ^ x)
+	}
+	return key
+}
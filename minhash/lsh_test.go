@@ -0,0 +1,41 @@
+package minhash
+
+import (
+	"testing"
+
+	"github.com/knakk/intset"
+	"github.com/knakk/specs"
+)
+
+func TestIndexQuery(t *testing.T) {
+	specs := specs.New(t)
+
+	h := NewHasher(128, 99)
+	idx := NewIndex(32, 4)
+
+	// sets of 100 integers, each overlapping the next one by 10 integers
+	for id := 0; id < 100; id++ {
+		idx.Insert(id, h.Signature(intset.NewBitSet(0).AddRange(id*90, id*90+99)))
+	}
+
+	// a near-duplicate of set 42
+	query := intset.NewBitSet(0).AddRange(42*90, 42*90+99).Remove(42*90, 42*90+1).Add(100000)
+	ids := idx.Query(h.Signature(query))
+	specs.Expect(ids, []int{42})
+
+	// the same set is always a candidate, even if inserted twice
+	idx.Insert(42, h.Signature(query))
+	specs.Expect(idx.Query(h.Signature(query)), []int{42})
+
+	// a disjoint set has no candidates
+	specs.Expect(len(idx.Query(h.Signature(intset.NewBitSet(0).AddRange(1000000, 1000099)))), 0)
+}
+
+func TestIndexShortSignature(t *testing.T) {
+	specs := specs.New(t)
+
+	defer func() {
+		specs.Expect(recover() != nil, true)
+	}()
+	NewIndex(32, 4).Insert(1, NewHasher(64, 1).Signature(intset.NewBitSet(0)))
+}
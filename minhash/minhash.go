@@ -0,0 +1,161 @@
+// Package minhash implements MinHash signatures of integer sets, and a banded
+// locality-sensitive hashing index to find the stored sets most similar to a
+// query set.
+//
+// The fraction of equal positions in the signatures of two sets estimates the
+// Jaccard index of the sets. Signatures are deterministic given the size and
+// seed of the Hasher, so they can be stored and compared across processes.
+package minhash
+
+import (
+	"math"
+
+	"github.com/knakk/intset"
+)
+
+// Signature is the MinHash signature of a set.
+type Signature []uint64
+
+// Hasher computes MinHash signatures of k values.
+//
+// A k-permutation Hasher hashes each integer of the set k times, with
+// independent hash functions, and keeps the minimum of each. A one-permutation
+// Hasher hashes each integer once, and keeps the minimum in each of k bins;
+// it is k times faster, at the price of a less accurate estimate for small
+// sets. Empty bins are filled from the next non-empty bin.
+type Hasher[T intset.Integer] struct {
+	k     int
+	seeds []uint64 // one per hash function
+}
+
+// NewHasher is the constructor for a k-permutation Hasher of int sets.
+func NewHasher(k int, seed uint64) *Hasher[int] {
+	return NewHasherOf[int](k, seed)
+}
+
+// NewHasherOf is the constructor for a k-permutation Hasher of sets of any
+// integer type.
+func NewHasherOf[T intset.Integer](k int, seed uint64) *Hasher[T] {
+	return new(Hasher[T]).init(k, k, seed)
+}
+
+// NewOnePermutationHasher is the constructor for a one-permutation Hasher of
+// int sets.
+func NewOnePermutationHasher(k int, seed uint64) *Hasher[int] {
+	return NewOnePermutationHasherOf[int](k, seed)
+}
+
+// NewOnePermutationHasherOf is the constructor for a one-permutation Hasher of
+// sets of any integer type.
+func NewOnePermutationHasherOf[T intset.Integer](k int, seed uint64) *Hasher[T] {
+	return new(Hasher[T]).init(k, 1, seed)
+}
+
+func (h *Hasher[T]) init(k, n int, seed uint64) *Hasher[T] {
+	if k <= 0 {
+		panic("minhash: signature size must be positive")
+	}
+	h.k = k
+	h.seeds = make([]uint64, n)
+	for i := range h.seeds {
+		h.seeds[i] = splitmix(&seed)
+	}
+	return h
+}
+
+// Size returns the number of values in the signatures.
+func (h *Hasher[T]) Size() int {
+	return h.k
+}
+
+// Signature returns the MinHash signature of a set. All the values of the
+// signature of an empty set are math.MaxUint64.
+func (h *Hasher[T]) Signature(set intset.Set[T]) Signature {
+	sig := make(Signature, h.k)
+	for i := range sig {
+		sig[i] = math.MaxUint64
+	}
+	if len(h.seeds) < h.k {
+		h.onePermutation(sig, set)
+		return sig
+	}
+	for v := range set.Values() {
+		for i, seed := range h.seeds {
+			if x := mix(uint64(v) ^ seed); x < sig[i] {
+				sig[i] = x
+			}
+		}
+	}
+	return sig
+}
+
+// onePermutation fills sig with the minimum of each bin, and fills the empty
+// bins by rotation: an empty bin takes the value of the next non-empty bin to
+// its right, offset by width for each bin between them. The values of the bins
+// are below width, so the offset values never overflow.
+func (h *Hasher[T]) onePermutation(sig Signature, set intset.Set[T]) {
+	k := uint64(h.k)
+	width := math.MaxUint64 / k
+	for v := range set.Values() {
+		x := mix(uint64(v) ^ h.seeds[0])
+		if bin := x % k; x/k < sig[bin] {
+			sig[bin] = x / k
+		}
+	}
+
+	start := -1
+	for i, x := range sig {
+		if x != math.MaxUint64 {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return // the set is empty
+	}
+	// walk leftwards from a non-empty bin, so that each empty bin follows
+	// a bin holding a value already
+	n := len(sig)
+	for d := 1; d < n; d++ {
+		if i := (start - d + n) % n; sig[i] == math.MaxUint64 {
+			sig[i] = sig[(i+1)%n] + width
+		}
+	}
+}
+
+// Similarity returns the fraction of equal values in two signatures, which is
+// an estimate of the Jaccard index of the sets. It panics if the signatures are
+// of different sizes.
+func Similarity(a, b Signature) float64 {
+	if len(a) != len(b) {
+		panic("minhash: signatures of different sizes")
+	}
+	if len(a) == 0 {
+		return 0
+	}
+	var n int
+	for i := range a {
+		if a[i] == b[i] {
+			n++
+		}
+	}
+	return float64(n) / float64(len(a))
+}
+
+// splitmix returns the next value of the SplitMix64 generator with the given
+// state.
+func splitmix(state *uint64) uint64 {
+	*state += 0x9e3779b97f4a7c15
+	return mix(*state)
+}
+
+// mix is the finalizer of SplitMix64. It is a bijection, so mixing the
+// integers xored with a seed permutes them.
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,88 @@
+package minhash
+
+import (
+	"math"
+	"testing"
+
+	"github.com/knakk/intset"
+	"github.com/knakk/specs"
+)
+
+// jaccard returns the exact Jaccard index of two sets.
+func jaccard(a, b intset.IntSet) float64 {
+	return float64(a.IntersectionCount(b)) / float64(a.UnionCount(b))
+}
+
+func TestSignatureDeterministic(t *testing.T) {
+	specs := specs.New(t)
+
+	set := intset.NewBitSet(0).Add(1, 5, 64, 1000)
+	for _, f := range []func(k int, seed uint64) *Hasher[int]{NewHasher, NewOnePermutationHasher} {
+		sig := f(64, 42).Signature(set)
+		specs.Expect(len(sig), 64)
+		specs.Expect(f(64, 42).Signature(intset.NewHashSet(0).Add(1000, 64, 5, 1)), sig)
+		specs.Expect(Similarity(sig, f(64, 43).Signature(set)) < 0.5, true)
+	}
+}
+
+func TestSignatureEstimate(t *testing.T) {
+	specs := specs.New(t)
+
+	a := intset.NewBitSet(0).AddRange(0, 999)
+	for _, b := range []intset.IntSet{
+		intset.NewBitSet(0).AddRange(0, 999),
+		intset.NewBitSet(0).AddRange(200, 1199),
+		intset.NewBitSet(0).AddRange(500, 1499),
+		intset.NewBitSet(0).AddRange(900, 1899),
+		intset.NewBitSet(0).AddRange(2000, 2999),
+	} {
+		want := jaccard(a, b)
+		for _, h := range []*Hasher[int]{NewHasher(256, 1), NewOnePermutationHasher(256, 1)} {
+			got := Similarity(h.Signature(a), h.Signature(b))
+			specs.Expect(math.Abs(got-want) < 0.1, true)
+		}
+	}
+}
+
+func TestOnePermutationDensification(t *testing.T) {
+	specs := specs.New(t)
+
+	// a set much smaller than the number of bins
+	h := NewOnePermutationHasher(128, 7)
+	sig := h.Signature(intset.NewBitSet(0).Add(3, 9))
+	for _, x := range sig {
+		specs.Expect(x != math.MaxUint64, true)
+	}
+	specs.Expect(Similarity(sig, h.Signature(intset.NewSliceSet(10).Add(3, 9))), 1.0)
+
+	empty := h.Signature(intset.NewBitSet(0))
+	for _, x := range empty {
+		specs.Expect(x, uint64(math.MaxUint64))
+	}
+}
+
+func TestSignatureOf(t *testing.T) {
+	specs := specs.New(t)
+
+	h := NewHasherOf[int8](16, 3)
+	a := intset.NewHashSetOf[int8](0).Add(-5, 0, 100)
+	b := intset.NewHashSetOf[int8](0).Add(100, -5, 0)
+	specs.Expect(h.Signature(a), h.Signature(b))
+}
+
+// Benchmarks
+
+func benchmarkSignature(b *testing.B, h *Hasher[int]) {
+	set := intset.NewBitSet(0).AddRange(0, 999)
+	for i := 0; i < b.N; i++ {
+		h.Signature(set)
+	}
+}
+
+func BenchmarkSignatureKPermutation(b *testing.B) {
+	benchmarkSignature(b, NewHasher(128, 1))
+}
+
+func BenchmarkSignatureOnePermutation(b *testing.B) {
+	benchmarkSignature(b, NewOnePermutationHasher(128, 1))
+}
@@ -0,0 +1,1455 @@
+package intset
+
+import (
+	"fmt"
+	"iter"
+	"math/bits"
+	"sort"
+	"strings"
+)
+
+// RoaringSet is an integer set implemented as a roaring bitmap. The 32-bit
+// integer space is split into chunks of 65536 integers, and the integers of
+// each chunk are stored in a container which is either a sorted array, a
+// bitmap or a list of runs, whichever is the most compact.
+//
+// Only integers in the range [0, 2^32) can be stored in a RoaringSet.
+type RoaringSet struct {
+	keys       []uint16 // the high 16 bits of each chunk, in ascending order
+	containers []container
+
+	// ranks holds the number of integers before each container. It is built
+	// by Rank and Select, and reset when the set is modified.
+	ranks rankIndex
+}
+
+// NewRoaringSet is the constructor for RoaringSet. Max is ignored in this set
+// implementation.
+func NewRoaringSet(max int) *RoaringSet {
+	return new(RoaringSet).init(max)
+}
+
+func (set *RoaringSet) init(max int) *RoaringSet {
+	set.keys = nil
+	set.containers = nil
+	set.ranks.reset()
+	return set
+}
+
+// roaringSplit splits i into the key of its chunk and its position within the
+// chunk. The last return value is false if i cannot be stored in a RoaringSet.
+func roaringSplit(i int) (uint16, uint16, bool) {
+	if i < 0 || uint64(i) > 1<<32-1 {
+		return 0, 0, false
+	}
+	return uint16(i >> 16), uint16(i), true
+}
+
+// find returns the index of the container with the given key, or the index
+// where it should be inserted if it is not present.
+func (set *RoaringSet) find(key uint16) (int, bool) {
+	k := sort.Search(len(set.keys), func(k int) bool { return set.keys[k] >= key })
+	return k, k < len(set.keys) && set.keys[k] == key
+}
+
+func (set *RoaringSet) insertAt(k int, key uint16, c container) {
+	set.keys = append(set.keys, 0)
+	copy(set.keys[k+1:], set.keys[k:])
+	set.keys[k] = key
+	set.containers = append(set.containers, nil)
+	copy(set.containers[k+1:], set.containers[k:])
+	set.containers[k] = c
+	set.ranks.reset()
+}
+
+func (set *RoaringSet) removeAt(k int) {
+	set.keys = append(set.keys[:k], set.keys[k+1:]...)
+	set.containers = append(set.containers[:k], set.containers[k+1:]...)
+	set.ranks.reset()
+}
+
+// append adds a container with a key larger than all the keys in the set,
+// unless it is empty.
+func (set *RoaringSet) append(key uint16, c container) {
+	if c.size() > 0 {
+		set.keys = append(set.keys, key)
+		set.containers = append(set.containers, c)
+		set.ranks.reset()
+	}
+}
+
+// Clear the set.
+func (set *RoaringSet) Clear() *RoaringSet {
+	set.keys = nil
+	set.containers = nil
+	set.ranks.reset()
+	return set
+}
+
+// Size returns the number of integers in the set.
+func (set *RoaringSet) Size() int {
+	var n int
+	for _, c := range set.containers {
+		n += c.size()
+	}
+	return n
+}
+
+// Add one or more integers to the set. It panics if an integer is negative
+// or does not fit in 32 bits.
+func (set *RoaringSet) Add(ints ...int) *RoaringSet {
+	for _, i := range ints {
+		key, x, ok := roaringSplit(i)
+		if !ok {
+			panic(fmt.Sprintf("intset: %d is out of range for RoaringSet", i))
+		}
+		k, found := set.find(key)
+		if !found {
+			set.insertAt(k, key, arrayContainer{x})
+			continue
+		}
+		set.containers[k] = set.containers[k].add(x)
+	}
+	set.ranks.reset()
+	return set
+}
+
+// Remove one or more integers from the set.
+func (set *RoaringSet) Remove(ints ...int) *RoaringSet {
+	for _, i := range ints {
+		key, x, ok := roaringSplit(i)
+		if !ok {
+			continue
+		}
+		if k, found := set.find(key); found {
+			set.containers[k] = set.containers[k].remove(x)
+			if set.containers[k].size() == 0 {
+				set.removeAt(k)
+			}
+		}
+	}
+	set.ranks.reset()
+	return set
+}
+
+// AddRange adds the integers from lo to hi, inclusive, to the set. It panics
+// if lo or hi is negative or does not fit in 32 bits.
+func (set *RoaringSet) AddRange(lo, hi int) *RoaringSet {
+	set.checkRange(lo, hi)
+	set.mutateRange(lo, hi, true, (*bitmapContainer).setRange)
+	return set
+}
+
+// RemoveRange removes the integers from lo to hi, inclusive, from the set.
+func (set *RoaringSet) RemoveRange(lo, hi int) *RoaringSet {
+	set.mutateRange(max(lo, 0), min(hi, 1<<32-1), false, (*bitmapContainer).clearRange)
+	return set
+}
+
+// FlipRange adds the integers from lo to hi, inclusive, which are not in the
+// set, and removes those which are. It panics if lo or hi is negative or does
+// not fit in 32 bits.
+func (set *RoaringSet) FlipRange(lo, hi int) *RoaringSet {
+	set.checkRange(lo, hi)
+	set.mutateRange(lo, hi, true, (*bitmapContainer).flipRange)
+	return set
+}
+
+// checkRange panics if lo or hi is out of range.
+func (set *RoaringSet) checkRange(lo, hi int) {
+	for _, i := range []int{lo, hi} {
+		if _, _, ok := roaringSplit(i); !ok {
+			panic(fmt.Sprintf("intset: %d is out of range for RoaringSet", i))
+		}
+	}
+}
+
+// mutateRange calls fn with a bitmap of each chunk overlapping the range from
+// lo to hi, inclusive, and the part of the range within the chunk. Chunks not
+// in the set are only passed to fn if create is true.
+func (set *RoaringSet) mutateRange(lo, hi int, create bool, fn func(bm *bitmapContainer, lo, hi int)) {
+	if hi < lo {
+		return
+	}
+	for key := lo >> 16; key <= hi>>16; key++ {
+		base := key << 16
+		k, found := set.find(uint16(key))
+		var bm *bitmapContainer
+		switch {
+		case found:
+			bm = newBitmapContainer(set.containers[k])
+		case create:
+			bm = &bitmapContainer{}
+		default:
+			continue
+		}
+		fn(bm, max(lo, base)-base, min(hi, base+0xffff)-base)
+		bm.count()
+		switch {
+		case bm.n == 0 && found:
+			set.removeAt(k)
+		case bm.n == 0:
+		case found:
+			set.containers[k] = optimize(bm)
+		default:
+			set.insertAt(k, uint16(key), optimize(bm))
+		}
+	}
+	set.ranks.reset()
+}
+
+// All returns a slice of all the integers in the set in ascending order.
+func (set *RoaringSet) All() []int {
+	var all []int
+	for k, c := range set.containers {
+		base := int(set.keys[k]) << 16
+		c.iterate(func(x uint16) bool {
+			all = append(all, base|int(x))
+			return true
+		})
+	}
+	return all
+}
+
+// Values returns an iterator over the integers in the set in ascending order.
+func (set *RoaringSet) Values() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for k, c := range set.containers {
+			base := int(set.keys[k]) << 16
+			if !c.iterate(func(x uint16) bool { return yield(base | int(x)) }) {
+				return
+			}
+		}
+	}
+}
+
+// each calls fn with each integer in the set in ascending order, until fn
+// returns false. Unlike Values, it calls the iterate method of each container
+// type directly, so that fn doesn't escape.
+func (set *RoaringSet) each(fn func(i int) bool) {
+	for k, c := range set.containers {
+		base := int(set.keys[k]) << 16
+		f := func(x uint16) bool { return fn(base | int(x)) }
+		var ok bool
+		switch c := c.(type) {
+		case arrayContainer:
+			ok = c.iterate(f)
+		case *bitmapContainer:
+			ok = c.iterate(f)
+		case runContainer:
+			ok = c.iterate(f)
+		}
+		if !ok {
+			return
+		}
+	}
+}
+
+// Backward returns an iterator over the integers in the set in descending
+// order.
+func (set *RoaringSet) Backward() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for k := len(set.containers) - 1; k >= 0; k-- {
+			base := int(set.keys[k]) << 16
+			if !set.containers[k].reverse(func(x uint16) bool { return yield(base | int(x)) }) {
+				return
+			}
+		}
+	}
+}
+
+// Min returns the smallest integer in the set. The second return value is
+// false if the set is empty.
+func (set *RoaringSet) Min() (int, bool) {
+	if len(set.containers) == 0 {
+		return 0, false
+	}
+	return int(set.keys[0])<<16 | int(set.containers[0].selectAt(0)), true
+}
+
+// Max returns the largest integer in the set. The second return value is false
+// if the set is empty.
+func (set *RoaringSet) Max() (int, bool) {
+	k := len(set.containers) - 1
+	if k < 0 {
+		return 0, false
+	}
+	c := set.containers[k]
+	return int(set.keys[k])<<16 | int(c.selectAt(c.size()-1)), true
+}
+
+// NextAfter returns the smallest integer in the set which is greater than i.
+// The second return value is false if there is no such integer.
+func (set *RoaringSet) NextAfter(i int) (int, bool) {
+	return set.Select(set.Rank(i))
+}
+
+// PrevBefore returns the largest integer in the set which is less than i. The
+// second return value is false if there is no such integer.
+func (set *RoaringSet) PrevBefore(i int) (int, bool) {
+	k := set.Rank(i) - 1
+	if set.Contains(i) {
+		k--
+	}
+	return set.Select(k)
+}
+
+// Contains returns true if all ints are in the set, otherwise false.
+func (set *RoaringSet) Contains(ints ...int) bool {
+	for _, i := range ints {
+		key, x, ok := roaringSplit(i)
+		if !ok {
+			return false
+		}
+		k, found := set.find(key)
+		if !found || !set.containers[k].contains(x) {
+			return false
+		}
+	}
+	return true
+}
+
+// buildRanks builds the rank index: the number of integers before each
+// container.
+func (set *RoaringSet) buildRanks() []int {
+	ranks := make([]int, len(set.containers))
+	var n int
+	for k, c := range set.containers {
+		ranks[k] = n
+		n += c.size()
+	}
+	return ranks
+}
+
+// Rank returns the number of integers in the set which are less than or equal
+// to i. Like Select, it finds the container of i in logarithmic time, using an
+// index which is reset when the set is modified.
+func (set *RoaringSet) Rank(i int) int {
+	if i < 0 {
+		return 0
+	}
+	key, x, ok := roaringSplit(i)
+	if !ok {
+		return set.Size()
+	}
+	k, found := set.find(key)
+	if k == len(set.containers) {
+		return set.Size()
+	}
+	n := set.ranks.get(set.buildRanks)[k]
+	if found {
+		n += set.containers[k].rank(x)
+	}
+	return n
+}
+
+// Select returns the k-th smallest integer in the set, counting from 0. The
+// second return value is false if k is not less than the size of the set.
+func (set *RoaringSet) Select(k int) (int, bool) {
+	if k < 0 || len(set.containers) == 0 {
+		return 0, false
+	}
+	ranks := set.ranks.get(set.buildRanks)
+	j := sort.Search(len(ranks), func(j int) bool { return ranks[j] > k }) - 1
+	if k -= ranks[j]; k >= set.containers[j].size() {
+		return 0, false
+	}
+	return int(set.keys[j])<<16 | int(set.containers[j].selectAt(k)), true
+}
+
+// Equal checks if two sets both contains all the same items.
+func (set *RoaringSet) Equal(other IntSet) bool {
+	o, ok := other.(*RoaringSet)
+	if !ok {
+		return set.Size() == other.Size() && set.SubsetOf(other)
+	}
+	if len(set.keys) != len(o.keys) {
+		return false
+	}
+	for k, c := range set.containers {
+		if set.keys[k] != o.keys[k] || c.size() != o.containers[k].size() ||
+			!subsetContainer(c, o.containers[k]) {
+			return false
+		}
+	}
+	return true
+}
+
+// SubsetOf checks if all items in set are also present in other set.
+func (set *RoaringSet) SubsetOf(other IntSet) bool {
+	o, ok := other.(*RoaringSet)
+	if !ok {
+		for _, i := range set.All() {
+			if !other.Contains(i) {
+				return false
+			}
+		}
+		return true
+	}
+	for k, c := range set.containers {
+		j, found := o.find(set.keys[k])
+		if !found || !subsetContainer(c, o.containers[j]) {
+			return false
+		}
+	}
+	return true
+}
+
+// SupersetOf checks if a set is a superset of another set.
+func (set *RoaringSet) SupersetOf(other IntSet) bool {
+	return other.SubsetOf(set)
+}
+
+// Union returns a new set which is the union of two sets.
+func (set *RoaringSet) Union(other IntSet) *RoaringSet {
+	o, ok := other.(*RoaringSet)
+	if !ok {
+		return set.Clone().Add(other.All()...)
+	}
+	result := NewRoaringSet(0)
+	i, j := 0, 0
+	for i < len(set.keys) && j < len(o.keys) {
+		switch a, b := set.keys[i], o.keys[j]; {
+		case a < b:
+			result.append(a, set.containers[i].clone())
+			i++
+		case a > b:
+			result.append(b, o.containers[j].clone())
+			j++
+		default:
+			result.append(a, unionContainers(set.containers[i], o.containers[j]))
+			i++
+			j++
+		}
+	}
+	for ; i < len(set.keys); i++ {
+		result.append(set.keys[i], set.containers[i].clone())
+	}
+	for ; j < len(o.keys); j++ {
+		result.append(o.keys[j], o.containers[j].clone())
+	}
+	return result
+}
+
+// Intersection returns a new set with integers common to both sets.
+func (set *RoaringSet) Intersection(other IntSet) *RoaringSet {
+	result := NewRoaringSet(0)
+	o, ok := other.(*RoaringSet)
+	if !ok {
+		for _, i := range set.All() {
+			if other.Contains(i) {
+				result.Add(i)
+			}
+		}
+		return result
+	}
+	for k, c := range set.containers {
+		if j, found := o.find(set.keys[k]); found {
+			result.append(set.keys[k], intersectContainers(c, o.containers[j]))
+		}
+	}
+	return result
+}
+
+// Difference returns a new set with the integers in set which are not in other.
+func (set *RoaringSet) Difference(other IntSet) *RoaringSet {
+	result := NewRoaringSet(0)
+	o, ok := other.(*RoaringSet)
+	if !ok {
+		for _, i := range set.All() {
+			if !other.Contains(i) {
+				result.Add(i)
+			}
+		}
+		return result
+	}
+	for k, c := range set.containers {
+		if j, found := o.find(set.keys[k]); found {
+			result.append(set.keys[k], differenceContainers(c, o.containers[j]))
+		} else {
+			result.append(set.keys[k], c.clone())
+		}
+	}
+	return result
+}
+
+// SymetricDifference returns a new set with the integers in current and other,
+// but not in both.
+func (set *RoaringSet) SymetricDifference(other IntSet) *RoaringSet {
+	o, ok := other.(*RoaringSet)
+	if !ok {
+		result := set.Clone()
+		for _, i := range other.All() {
+			if set.Contains(i) {
+				result.Remove(i)
+			} else {
+				result.Add(i)
+			}
+		}
+		return result
+	}
+	result := NewRoaringSet(0)
+	i, j := 0, 0
+	for i < len(set.keys) && j < len(o.keys) {
+		switch a, b := set.keys[i], o.keys[j]; {
+		case a < b:
+			result.append(a, set.containers[i].clone())
+			i++
+		case a > b:
+			result.append(b, o.containers[j].clone())
+			j++
+		default:
+			result.append(a, xorContainers(set.containers[i], o.containers[j]))
+			i++
+			j++
+		}
+	}
+	for ; i < len(set.keys); i++ {
+		result.append(set.keys[i], set.containers[i].clone())
+	}
+	for ; j < len(o.keys); j++ {
+		result.append(o.keys[j], o.containers[j].clone())
+	}
+	return result
+}
+
+// UnionWith adds the integers in other to the set. It is the in-place variant
+// of Union.
+func (set *RoaringSet) UnionWith(other IntSet) *RoaringSet {
+	o, ok := other.(*RoaringSet)
+	if !ok {
+		for i := range other.Values() {
+			set.Add(i)
+		}
+		return set
+	}
+	set.mergeWith(o, unionInto)
+	return set
+}
+
+// IntersectWith removes the integers which are not in other from the set. It
+// is the in-place variant of Intersection.
+func (set *RoaringSet) IntersectWith(other IntSet) *RoaringSet {
+	o, ok := other.(*RoaringSet)
+	if !ok {
+		for _, i := range set.All() {
+			if !other.Contains(i) {
+				set.Remove(i)
+			}
+		}
+		return set
+	}
+	n := 0
+	for k, c := range set.containers {
+		if j, found := o.find(set.keys[k]); found {
+			if c = intersectInto(c, o.containers[j]); c.size() > 0 {
+				set.keys[n], set.containers[n] = set.keys[k], c
+				n++
+			}
+		}
+	}
+	clear(set.containers[n:])
+	set.keys, set.containers = set.keys[:n], set.containers[:n]
+	set.ranks.reset()
+	return set
+}
+
+// DifferenceWith removes the integers in other from the set. It is the
+// in-place variant of Difference.
+func (set *RoaringSet) DifferenceWith(other IntSet) *RoaringSet {
+	o, ok := other.(*RoaringSet)
+	if !ok {
+		for i := range other.Values() {
+			set.Remove(i)
+		}
+		return set
+	}
+	n := 0
+	for k, c := range set.containers {
+		if j, found := o.find(set.keys[k]); found {
+			c = differenceInto(c, o.containers[j])
+		}
+		if c.size() > 0 {
+			set.keys[n], set.containers[n] = set.keys[k], c
+			n++
+		}
+	}
+	clear(set.containers[n:])
+	set.keys, set.containers = set.keys[:n], set.containers[:n]
+	set.ranks.reset()
+	return set
+}
+
+// SymmetricDifferenceWith adds the integers in other which are not in the set,
+// and removes those which are. It is the in-place variant of
+// SymetricDifference.
+func (set *RoaringSet) SymmetricDifferenceWith(other IntSet) *RoaringSet {
+	o, ok := other.(*RoaringSet)
+	if !ok {
+		for i := range other.Values() {
+			if set.Contains(i) {
+				set.Remove(i)
+			} else {
+				set.Add(i)
+			}
+		}
+		return set
+	}
+	if o == set {
+		return set.Clear()
+	}
+	set.mergeWith(o, xorInto)
+	return set
+}
+
+// mergeWith merges the containers of other into the set. Containers with the
+// same key are combined with fn, and dropped if the result is empty.
+func (set *RoaringSet) mergeWith(other *RoaringSet, fn func(a, b container) container) {
+	if other == set {
+		return
+	}
+	keys := make([]uint16, 0, len(set.keys)+len(other.keys))
+	containers := make([]container, 0, cap(keys))
+	i, j := 0, 0
+	for i < len(set.keys) || j < len(other.keys) {
+		switch {
+		case j == len(other.keys) || (i < len(set.keys) && set.keys[i] < other.keys[j]):
+			keys, containers = append(keys, set.keys[i]), append(containers, set.containers[i])
+			i++
+		case i == len(set.keys) || set.keys[i] > other.keys[j]:
+			keys, containers = append(keys, other.keys[j]), append(containers, other.containers[j].clone())
+			j++
+		default:
+			if c := fn(set.containers[i], other.containers[j]); c.size() > 0 {
+				keys, containers = append(keys, set.keys[i]), append(containers, c)
+			}
+			i++
+			j++
+		}
+	}
+	set.keys, set.containers = keys, containers
+	set.ranks.reset()
+}
+
+// IntersectionCount returns the number of integers common to both sets,
+// without building the intersection.
+func (set *RoaringSet) IntersectionCount(other IntSet) int {
+	o, ok := other.(*RoaringSet)
+	if !ok {
+		return intersectionCount[int](set, other)
+	}
+	var n int
+	for k, c := range set.containers {
+		if j, found := o.find(set.keys[k]); found {
+			n += intersectionCountContainers(c, o.containers[j], false)
+		}
+	}
+	return n
+}
+
+// UnionCount returns the number of integers in the union of two sets, without
+// building the union.
+func (set *RoaringSet) UnionCount(other IntSet) int {
+	return set.Size() + other.Size() - set.IntersectionCount(other)
+}
+
+// DifferenceCount returns the number of integers in set which are not in
+// other, without building the difference.
+func (set *RoaringSet) DifferenceCount(other IntSet) int {
+	return set.Size() - set.IntersectionCount(other)
+}
+
+// SymmetricDifferenceCount returns the number of integers in current and
+// other, but not in both, without building the symmetric difference.
+func (set *RoaringSet) SymmetricDifferenceCount(other IntSet) int {
+	return set.Size() + other.Size() - 2*set.IntersectionCount(other)
+}
+
+// Intersects returns true if the sets have at least one integer in common.
+func (set *RoaringSet) Intersects(other IntSet) bool {
+	o, ok := other.(*RoaringSet)
+	if !ok {
+		return intersects[int](set, other)
+	}
+	for k, c := range set.containers {
+		if j, found := o.find(set.keys[k]); found {
+			if intersectionCountContainers(c, o.containers[j], true) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Clone returns a new set which is a clone of current set.
+func (set *RoaringSet) Clone() *RoaringSet {
+	result := NewRoaringSet(0)
+	result.keys = append(result.keys, set.keys...)
+	for _, c := range set.containers {
+		result.containers = append(result.containers, c.clone())
+	}
+	return result
+}
+
+// RunOptimize converts each container in the set to the most compact of the
+// array, bitmap and run representations. Add and Remove only switch between
+// arrays and bitmaps, so sets containing long runs of integers should be
+// optimized after they have been filled.
+func (set *RoaringSet) RunOptimize() *RoaringSet {
+	for k, c := range set.containers {
+		set.containers[k] = optimize(c)
+	}
+	return set
+}
+
+// Insert adds one or more integers to the set. It is the Set adapter for Add.
+func (set *RoaringSet) Insert(ints ...int) {
+	set.Add(ints...)
+}
+
+// Delete removes one or more integers from the set. It is the Set adapter
+// for Remove.
+func (set *RoaringSet) Delete(ints ...int) {
+	set.Remove(ints...)
+}
+
+// CloneSet is the Set adapter for Clone.
+func (set *RoaringSet) CloneSet() IntSet {
+	return set.Clone()
+}
+
+// UnionSet is the Set adapter for Union.
+func (set *RoaringSet) UnionSet(other IntSet) IntSet {
+	return set.Union(other)
+}
+
+// IntersectionSet is the Set adapter for Intersection.
+func (set *RoaringSet) IntersectionSet(other IntSet) IntSet {
+	return set.Intersection(other)
+}
+
+// DifferenceSet is the Set adapter for Difference.
+func (set *RoaringSet) DifferenceSet(other IntSet) IntSet {
+	return set.Difference(other)
+}
+
+// SymetricDifferenceSet is the Set adapter for SymetricDifference.
+func (set *RoaringSet) SymetricDifferenceSet(other IntSet) IntSet {
+	return set.SymetricDifference(other)
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (set *RoaringSet) MarshalBinary() ([]byte, error) {
+	return marshalBinary(tagRoaringSet, set.All()), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface. The
+// data may have been marshalled from any set type.
+func (set *RoaringSet) UnmarshalBinary(data []byte) error {
+	ints, err := unmarshalBinary[int](data)
+	if err != nil {
+		return err
+	}
+	return set.load(ints)
+}
+
+// MarshalJSON implements the json.Marshaler interface. The set is marshalled
+// as an array of integers in ascending order.
+func (set *RoaringSet) MarshalJSON() ([]byte, error) {
+	return marshalJSON(set.All()), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It accepts an array
+// of integers or a string in the form produced by MarshalText.
+func (set *RoaringSet) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, set.load, set.loadRuns)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface. The set is
+// marshalled as a comma separated list of integers and ranges of integers in
+// ascending order, e.g. "1-5,9".
+func (set *RoaringSet) MarshalText() ([]byte, error) {
+	return marshalText(set.All()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface. It accepts
+// the form produced by MarshalText as well as the one produced by String.
+func (set *RoaringSet) UnmarshalText(text []byte) error {
+	return unmarshalText(text, set.loadRuns)
+}
+
+// load replaces the contents of the set with the given integers.
+func (set *RoaringSet) load(ints []int) error {
+	for _, i := range ints {
+		if _, _, ok := roaringSplit(i); !ok {
+			return fmt.Errorf("intset: %d is out of range for RoaringSet", i)
+		}
+	}
+	set.Clear().Add(ints...)
+	return nil
+}
+
+// loadRuns replaces the contents of the set with the integers of the given
+// ranges.
+func (set *RoaringSet) loadRuns(runs []Interval[int]) error {
+	for _, r := range runs {
+		for _, i := range []int{r.Lo, r.Hi} {
+			if _, _, ok := roaringSplit(i); !ok {
+				return fmt.Errorf("intset: %d is out of range for RoaringSet", i)
+			}
+		}
+	}
+	set.Clear()
+	for _, r := range runs {
+		set.AddRange(r.Lo, r.Hi)
+	}
+	return nil
+}
+
+// String implements the Stringer interface for RoaringSet.
+func (set *RoaringSet) String() string {
+	items := make([]string, 0, set.Size())
+
+	for _, i := range set.All() {
+		items = append(items, fmt.Sprintf("%v", i))
+	}
+	return fmt.Sprintf("Set{%s}", strings.Join(items, ", "))
+}
+
+// Containers
+
+const (
+	// arrayMaxSize is the maximum number of integers in an array container.
+	// Beyond this a bitmap takes up less space.
+	arrayMaxSize = 4096
+
+	bitmapWords = 1 << 16 / 64
+)
+
+// container holds the low 16 bits of the integers in a chunk of a
+// RoaringSet. The mutating methods return the container to use afterwards,
+// which is a different one if the representation has changed.
+type container interface {
+	add(x uint16) container
+	remove(x uint16) container
+	contains(x uint16) bool
+	size() int
+	numRuns() int
+	clone() container
+
+	// iterate calls fn for each integer in ascending order, until fn returns
+	// false. It returns false if the iteration was stopped.
+	iterate(fn func(x uint16) bool) bool
+
+	// reverse is like iterate, but in descending order.
+	reverse(fn func(x uint16) bool) bool
+
+	// rank returns the number of integers less than or equal to x.
+	rank(x uint16) int
+
+	// selectAt returns the k-th smallest integer, counting from 0. K must be
+	// less than the size of the container.
+	selectAt(k int) uint16
+}
+
+// optimize returns c converted to its most compact representation.
+func optimize(c container) container {
+	n, runs := c.size(), c.numRuns()
+	switch {
+	case 4*runs < min(2*n, 2*arrayMaxSize):
+		return toRunContainer(c)
+	case n <= arrayMaxSize:
+		return toArrayContainer(c)
+	}
+	return toBitmapContainer(c)
+}
+
+func toArrayContainer(c container) arrayContainer {
+	if a, ok := c.(arrayContainer); ok {
+		return a
+	}
+	a := make(arrayContainer, 0, c.size())
+	c.iterate(func(x uint16) bool {
+		a = append(a, x)
+		return true
+	})
+	return a
+}
+
+func toBitmapContainer(c container) *bitmapContainer {
+	if bm, ok := c.(*bitmapContainer); ok {
+		return bm
+	}
+	return newBitmapContainer(c)
+}
+
+func toRunContainer(c container) runContainer {
+	if r, ok := c.(runContainer); ok {
+		return r
+	}
+	r := make(runContainer, 0, c.numRuns())
+	c.iterate(func(x uint16) bool {
+		if n := len(r); n > 0 && int(r[n-1].last)+1 == int(x) {
+			r[n-1].last = x
+		} else {
+			r = append(r, run{x, x})
+		}
+		return true
+	})
+	return r
+}
+
+// newBitmapContainer returns a new bitmap container with the integers in c.
+func newBitmapContainer(c container) *bitmapContainer {
+	bm := &bitmapContainer{}
+	switch c := c.(type) {
+	case *bitmapContainer:
+		*bm = *c
+	case runContainer:
+		for _, r := range c {
+			bm.setRange(int(r.start), int(r.last))
+		}
+		bm.count()
+	default:
+		c.iterate(func(x uint16) bool {
+			bm.add(x)
+			return true
+		})
+	}
+	return bm
+}
+
+func subsetContainer(a, b container) bool {
+	if a.size() > b.size() {
+		return false
+	}
+	if x, ok := a.(*bitmapContainer); ok {
+		if y, ok := b.(*bitmapContainer); ok {
+			for k, w := range x.words {
+				if w&^y.words[k] != 0 {
+					return false
+				}
+			}
+			return true
+		}
+	}
+	return a.iterate(b.contains)
+}
+
+func unionContainers(a, b container) container {
+	if x, ok := a.(arrayContainer); ok {
+		if y, ok := b.(arrayContainer); ok && len(x)+len(y) <= arrayMaxSize {
+			return mergeArrays(x, y)
+		}
+	}
+	bm := newBitmapContainer(a)
+	bm.or(toBitmapContainer(b))
+	return optimize(bm)
+}
+
+func intersectContainers(a, b container) container {
+	if _, ok := b.(arrayContainer); ok {
+		a, b = b, a
+	}
+	if x, ok := a.(arrayContainer); ok {
+		result := make(arrayContainer, 0, len(x))
+		for _, v := range x {
+			if b.contains(v) {
+				result = append(result, v)
+			}
+		}
+		return result
+	}
+	bm := newBitmapContainer(a)
+	bm.and(toBitmapContainer(b))
+	return optimize(bm)
+}
+
+func differenceContainers(a, b container) container {
+	if x, ok := a.(arrayContainer); ok {
+		result := make(arrayContainer, 0, len(x))
+		for _, v := range x {
+			if !b.contains(v) {
+				result = append(result, v)
+			}
+		}
+		return result
+	}
+	bm := newBitmapContainer(a)
+	bm.andNot(toBitmapContainer(b))
+	return optimize(bm)
+}
+
+func xorContainers(a, b container) container {
+	if x, ok := a.(arrayContainer); ok {
+		if y, ok := b.(arrayContainer); ok && len(x)+len(y) <= arrayMaxSize {
+			return xorArrays(x, y)
+		}
+	}
+	bm := newBitmapContainer(a)
+	bm.xor(toBitmapContainer(b))
+	return optimize(bm)
+}
+
+// intersectionCountContainers returns the number of integers common to a and
+// b. If first is true, it stops counting at the first common integer.
+func intersectionCountContainers(a, b container, first bool) int {
+	x, ok1 := a.(*bitmapContainer)
+	y, ok2 := b.(*bitmapContainer)
+	var n int
+	if ok1 && ok2 {
+		for k, w := range x.words {
+			if n += bits.OnesCount64(w & y.words[k]); n > 0 && first {
+				break
+			}
+		}
+		return n
+	}
+	if a.size() > b.size() {
+		a, b = b, a
+	}
+	a.iterate(func(x uint16) bool {
+		if b.contains(x) {
+			n++
+		}
+		return n == 0 || !first
+	})
+	return n
+}
+
+// The following functions combine containers like the ones above, but modify
+// a in place if it is a bitmap.
+
+func unionInto(a, b container) container {
+	if bm, ok := a.(*bitmapContainer); ok {
+		bm.or(toBitmapContainer(b))
+		return optimize(bm)
+	}
+	return unionContainers(a, b)
+}
+
+func intersectInto(a, b container) container {
+	if bm, ok := a.(*bitmapContainer); ok {
+		bm.and(toBitmapContainer(b))
+		return optimize(bm)
+	}
+	return intersectContainers(a, b)
+}
+
+func differenceInto(a, b container) container {
+	if bm, ok := a.(*bitmapContainer); ok {
+		bm.andNot(toBitmapContainer(b))
+		return optimize(bm)
+	}
+	return differenceContainers(a, b)
+}
+
+func xorInto(a, b container) container {
+	if bm, ok := a.(*bitmapContainer); ok {
+		bm.xor(toBitmapContainer(b))
+		return optimize(bm)
+	}
+	return xorContainers(a, b)
+}
+
+// arrayContainer is a sorted slice of integers, used for sparse chunks.
+type arrayContainer []uint16
+
+func (a arrayContainer) search(x uint16) int {
+	return sort.Search(len(a), func(i int) bool { return a[i] >= x })
+}
+
+func (a arrayContainer) add(x uint16) container {
+	i := a.search(x)
+	if i < len(a) && a[i] == x {
+		return a
+	}
+	if len(a) >= arrayMaxSize {
+		return optimize(newBitmapContainer(a).add(x))
+	}
+	a = append(a, 0)
+	copy(a[i+1:], a[i:])
+	a[i] = x
+	return a
+}
+
+func (a arrayContainer) remove(x uint16) container {
+	i := a.search(x)
+	if i < len(a) && a[i] == x {
+		return append(a[:i], a[i+1:]...)
+	}
+	return a
+}
+
+func (a arrayContainer) contains(x uint16) bool {
+	i := a.search(x)
+	return i < len(a) && a[i] == x
+}
+
+func (a arrayContainer) size() int {
+	return len(a)
+}
+
+func (a arrayContainer) numRuns() int {
+	var runs int
+	for i, x := range a {
+		if i == 0 || a[i-1]+1 != x {
+			runs++
+		}
+	}
+	return runs
+}
+
+func (a arrayContainer) clone() container {
+	return append(arrayContainer(nil), a...)
+}
+
+func (a arrayContainer) iterate(fn func(x uint16) bool) bool {
+	for _, x := range a {
+		if !fn(x) {
+			return false
+		}
+	}
+	return true
+}
+
+func (a arrayContainer) reverse(fn func(x uint16) bool) bool {
+	for i := len(a) - 1; i >= 0; i-- {
+		if !fn(a[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (a arrayContainer) rank(x uint16) int {
+	return sort.Search(len(a), func(i int) bool { return a[i] > x })
+}
+
+func (a arrayContainer) selectAt(k int) uint16 {
+	return a[k]
+}
+
+func mergeArrays(a, b arrayContainer) arrayContainer {
+	result := make(arrayContainer, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] < b[j]:
+			result = append(result, a[i])
+			i++
+		case a[i] > b[j]:
+			result = append(result, b[j])
+			j++
+		default:
+			result = append(result, a[i])
+			i++
+			j++
+		}
+	}
+	result = append(result, a[i:]...)
+	return append(result, b[j:]...)
+}
+
+func xorArrays(a, b arrayContainer) arrayContainer {
+	result := make(arrayContainer, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] < b[j]:
+			result = append(result, a[i])
+			i++
+		case a[i] > b[j]:
+			result = append(result, b[j])
+			j++
+		default:
+			i++
+			j++
+		}
+	}
+	result = append(result, a[i:]...)
+	return append(result, b[j:]...)
+}
+
+// bitmapContainer has one bit for each integer in the chunk, used for dense
+// chunks.
+type bitmapContainer struct {
+	words [bitmapWords]uint64
+	n     int
+}
+
+func (bm *bitmapContainer) add(x uint16) container {
+	if w := &bm.words[x/64]; *w&(1<<(x%64)) == 0 {
+		*w |= 1 << (x % 64)
+		bm.n++
+	}
+	return bm
+}
+
+func (bm *bitmapContainer) remove(x uint16) container {
+	if w := &bm.words[x/64]; *w&(1<<(x%64)) != 0 {
+		*w &^= 1 << (x % 64)
+		bm.n--
+	}
+	if bm.n <= arrayMaxSize {
+		return toArrayContainer(bm)
+	}
+	return bm
+}
+
+func (bm *bitmapContainer) contains(x uint16) bool {
+	return bm.words[x/64]&(1<<(x%64)) != 0
+}
+
+func (bm *bitmapContainer) size() int {
+	return bm.n
+}
+
+func (bm *bitmapContainer) numRuns() int {
+	var runs int
+	var carry uint64
+	for _, w := range bm.words {
+		// count the bits which are set while the preceding bit is not
+		runs += bits.OnesCount64(w &^ (w<<1 | carry))
+		carry = w >> 63
+	}
+	return runs
+}
+
+func (bm *bitmapContainer) clone() container {
+	c := *bm
+	return &c
+}
+
+func (bm *bitmapContainer) iterate(fn func(x uint16) bool) bool {
+	for k, w := range bm.words {
+		for w != 0 {
+			if !fn(uint16(k*64 + bits.TrailingZeros64(w))) {
+				return false
+			}
+			w &= w - 1
+		}
+	}
+	return true
+}
+
+func (bm *bitmapContainer) reverse(fn func(x uint16) bool) bool {
+	for k := len(bm.words) - 1; k >= 0; k-- {
+		for w := bm.words[k]; w != 0; {
+			j := 63 - bits.LeadingZeros64(w)
+			if !fn(uint16(k*64 + j)) {
+				return false
+			}
+			w &^= 1 << uint(j)
+		}
+	}
+	return true
+}
+
+func (bm *bitmapContainer) rank(x uint16) int {
+	var n int
+	for _, w := range bm.words[:x/64] {
+		n += bits.OnesCount64(w)
+	}
+	return n + bits.OnesCount64(bm.words[x/64]&(^uint64(0)>>(63-x%64)))
+}
+
+func (bm *bitmapContainer) selectAt(k int) uint16 {
+	for j, w := range bm.words {
+		if n := bits.OnesCount64(w); k >= n {
+			k -= n
+			continue
+		}
+		return uint16(j*64 + selectBit(w, k))
+	}
+	return 0
+}
+
+// setRange sets the bits from lo to hi, inclusive. It does not update the
+// cardinality.
+func (bm *bitmapContainer) setRange(lo, hi int) {
+	for k := lo / 64; k <= hi/64; k++ {
+		bm.words[k] |= rangeMask(k, lo, hi)
+	}
+}
+
+// clearRange clears the bits from lo to hi, inclusive. It does not update the
+// cardinality.
+func (bm *bitmapContainer) clearRange(lo, hi int) {
+	for k := lo / 64; k <= hi/64; k++ {
+		bm.words[k] &^= rangeMask(k, lo, hi)
+	}
+}
+
+// flipRange flips the bits from lo to hi, inclusive. It does not update the
+// cardinality.
+func (bm *bitmapContainer) flipRange(lo, hi int) {
+	for k := lo / 64; k <= hi/64; k++ {
+		bm.words[k] ^= rangeMask(k, lo, hi)
+	}
+}
+
+// count recalculates the cardinality.
+func (bm *bitmapContainer) count() {
+	bm.n = 0
+	for _, w := range bm.words {
+		bm.n += bits.OnesCount64(w)
+	}
+}
+
+func (bm *bitmapContainer) or(other *bitmapContainer) {
+	for k, w := range other.words {
+		bm.words[k] |= w
+	}
+	bm.count()
+}
+
+func (bm *bitmapContainer) and(other *bitmapContainer) {
+	for k, w := range other.words {
+		bm.words[k] &= w
+	}
+	bm.count()
+}
+
+func (bm *bitmapContainer) andNot(other *bitmapContainer) {
+	for k, w := range other.words {
+		bm.words[k] &^= w
+	}
+	bm.count()
+}
+
+func (bm *bitmapContainer) xor(other *bitmapContainer) {
+	for k, w := range other.words {
+		bm.words[k] ^= w
+	}
+	bm.count()
+}
+
+// runContainer is a sorted list of non-overlapping, non-adjacent runs of
+// integers, used for chunks with long runs.
+type runContainer []run
+
+// run is an interval of integers from start to last, inclusive.
+type run struct {
+	start, last uint16
+}
+
+// search returns the index of the last run starting at or before x, or -1.
+func (r runContainer) search(x uint16) int {
+	return sort.Search(len(r), func(i int) bool { return r[i].start > x }) - 1
+}
+
+func (r runContainer) add(x uint16) container {
+	i := r.search(x)
+	if i >= 0 && x <= r[i].last {
+		return r
+	}
+	extendsPrev := i >= 0 && int(r[i].last)+1 == int(x)
+	extendsNext := i+1 < len(r) && int(x)+1 == int(r[i+1].start)
+	switch {
+	case extendsPrev && extendsNext:
+		r[i].last = r[i+1].last
+		r = append(r[:i+1], r[i+2:]...)
+	case extendsPrev:
+		r[i].last = x
+	case extendsNext:
+		r[i+1].start = x
+	default:
+		r = append(r, run{})
+		copy(r[i+2:], r[i+1:])
+		r[i+1] = run{x, x}
+		if 4*len(r) > 2*arrayMaxSize {
+			return optimize(r)
+		}
+	}
+	return r
+}
+
+func (r runContainer) remove(x uint16) container {
+	i := r.search(x)
+	if i < 0 || x > r[i].last {
+		return r
+	}
+	switch {
+	case r[i].start == r[i].last:
+		r = append(r[:i], r[i+1:]...)
+	case x == r[i].start:
+		r[i].start++
+	case x == r[i].last:
+		r[i].last--
+	default:
+		r = append(r, run{})
+		copy(r[i+2:], r[i+1:])
+		r[i+1] = run{x + 1, r[i].last}
+		r[i].last = x - 1
+		if 4*len(r) > 2*arrayMaxSize {
+			return optimize(r)
+		}
+	}
+	return r
+}
+
+func (r runContainer) contains(x uint16) bool {
+	i := r.search(x)
+	return i >= 0 && x <= r[i].last
+}
+
+func (r runContainer) size() int {
+	var n int
+	for _, v := range r {
+		n += int(v.last-v.start) + 1
+	}
+	return n
+}
+
+func (r runContainer) numRuns() int {
+	return len(r)
+}
+
+func (r runContainer) clone() container {
+	return append(runContainer(nil), r...)
+}
+
+func (r runContainer) iterate(fn func(x uint16) bool) bool {
+	for _, v := range r {
+		for x := int(v.start); x <= int(v.last); x++ {
+			if !fn(uint16(x)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (r runContainer) reverse(fn func(x uint16) bool) bool {
+	for i := len(r) - 1; i >= 0; i-- {
+		for x := int(r[i].last); x >= int(r[i].start); x-- {
+			if !fn(uint16(x)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (r runContainer) rank(x uint16) int {
+	var n int
+	for _, v := range r {
+		if v.start > x {
+			break
+		}
+		n += min(int(v.last), int(x)) - int(v.start) + 1
+	}
+	return n
+}
+
+func (r runContainer) selectAt(k int) uint16 {
+	for _, v := range r {
+		if n := int(v.last-v.start) + 1; k >= n {
+			k -= n
+			continue
+		}
+		return v.start + uint16(k)
+	}
+	return 0
+}
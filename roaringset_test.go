@@ -0,0 +1,578 @@
+package intset
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/knakk/specs"
+)
+
+func TestRoaringSetAdd(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewRoaringSet(10).Add(1, 2, 5, 2)
+
+	specs.Expect(set.Contains(1, 2, 5), true)
+	specs.Expect(set.Size(), 3)
+}
+
+func TestRoaringSetRemove(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewRoaringSet(10).Add(3, 1)
+	specs.Expect(set.Contains(3, 1), true)
+	set.Remove(1, 3)
+	specs.Expect(set.Contains(1, 3), false)
+}
+
+func TestRoaringSetRanges(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewRoaringSet(200).AddRange(10, 140)
+	specs.Expect(set.Size(), 131)
+	specs.Expect(set.Contains(10, 63, 64, 140), true)
+	specs.Expect(set.Contains(9), false)
+	specs.Expect(set.Contains(141), false)
+
+	set.RemoveRange(20, 129)
+	specs.Expect(set.Size(), 21)
+	specs.Expect(set.Contains(19, 130), true)
+	specs.Expect(set.Contains(20), false)
+	specs.Expect(set.Contains(129), false)
+
+	set.FlipRange(15, 25)
+	specs.Expect(set.Size(), 22)
+	specs.Expect(set.Contains(14, 20, 25), true)
+	specs.Expect(set.Contains(15), false)
+	specs.Expect(set.Contains(26), false)
+
+	set.RemoveRange(-5, 1000)
+	specs.Expect(set.Size(), 0)
+	set.AddRange(5, 4)
+	specs.Expect(set.Size(), 0)
+
+	// ranges spanning several chunks
+	set.AddRange(1<<16-10, 3<<16+9)
+	specs.Expect(set.Size(), 2<<16+20)
+	set.RemoveRange(1<<16+10, 3<<16-11)
+	specs.Expect(set.Size(), 40)
+	specs.Expect(set.Contains(1<<16+9, 3<<16-10), true)
+	set.FlipRange(0, 4<<16-1)
+	specs.Expect(set.Size(), 4<<16-40)
+}
+
+func TestRoaringSetContains(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewRoaringSet(10).Add(1, 2, 3).Remove(2)
+
+	specs.Expect(set.Contains(2), false)
+	specs.Expect(set.Contains(1, 3), true)
+	specs.Expect(set.Contains(1, 2, 3), false)
+}
+
+func TestRoaringSetClear(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewRoaringSet(10).Add(1, 2, 3, 4, 5).Clear()
+
+	specs.Expect(set.Contains(1, 2, 3, 4, 5), false)
+	specs.Expect(set.Size(), 0)
+}
+
+func TestRoaringSetSize(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewRoaringSet(10).Add(11, 2, 3)
+	specs.Expect(set.Size(), 3)
+	set.Remove(2)
+	specs.Expect(set.Size(), 2)
+}
+
+func TestRoaringSetAll(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewRoaringSet(10).Add(99, 1, 5)
+	all := set.All()
+
+	specs.Expect(len(all), 3)
+	specs.Expect(all[0], 1)
+	specs.Expect(all[1], 5)
+	specs.Expect(all[2], 99)
+}
+
+func TestRoaringSetValues(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewRoaringSet(10).Add(99, 1, 5)
+	var all []int
+	for i := range set.Values() {
+		all = append(all, i)
+	}
+	specs.Expect(all, []int{1, 5, 99})
+
+	all = nil
+	for i := range set.Values() {
+		all = append(all, i)
+		if i >= 5 {
+			break
+		}
+	}
+	specs.Expect(all, []int{1, 5})
+}
+
+func TestRoaringSetBackward(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewRoaringSet(10).Add(99, 1, 5)
+	var all []int
+	for i := range set.Backward() {
+		all = append(all, i)
+	}
+	specs.Expect(all, []int{99, 5, 1})
+
+	all = nil
+	for i := range set.Backward() {
+		all = append(all, i)
+		if i <= 5 {
+			break
+		}
+	}
+	specs.Expect(all, []int{99, 5})
+}
+
+func TestRoaringSetRank(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewRoaringSet(0).Add(99, 1, 5, 64, 1000)
+
+	specs.Expect(set.Rank(-1), 0)
+	specs.Expect(set.Rank(0), 0)
+	specs.Expect(set.Rank(1), 1)
+	specs.Expect(set.Rank(63), 2)
+	specs.Expect(set.Rank(64), 3)
+	specs.Expect(set.Rank(999), 4)
+	specs.Expect(set.Rank(1000), 5)
+	specs.Expect(set.Rank(100000), 5)
+
+	set.Remove(5)
+	specs.Expect(set.Rank(64), 2)
+}
+
+func TestRoaringSetSelect(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewRoaringSet(0)
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		set.Add(r.Intn(1000000))
+	}
+	for i := 0; i < 10000; i++ {
+		set.Add(200000 + 2*i)
+	}
+	for i := 0; i < 100000; i++ {
+		set.Add(400000 + i)
+	}
+	set.RunOptimize()
+	for k, want := range set.All() {
+		i, ok := set.Select(k)
+		specs.Expect(ok, true)
+		specs.Expect(i, want)
+		specs.Expect(set.Rank(i), k+1)
+	}
+	_, ok := set.Select(set.Size())
+	specs.Expect(ok, false)
+	_, ok = set.Select(-1)
+	specs.Expect(ok, false)
+
+	set.Remove(set.All()[0])
+	i, _ := set.Select(0)
+	specs.Expect(i, set.All()[0])
+}
+
+func TestRoaringSetEqual(t *testing.T) {
+	specs := specs.New(t)
+
+	setA := NewRoaringSet(10).Add(1, 2)
+	setB := NewRoaringSet(10).Add(2, 1, 1)
+	setC := NewRoaringSet(10).Add(1, 3)
+
+	specs.Expect(setA.Equal(setB), true)
+	specs.Expect(setA.Equal(setC), false)
+}
+
+func TestRoaringSetSubsetOf(t *testing.T) {
+	specs := specs.New(t)
+
+	setA := NewRoaringSet(10).Add(1, 2)
+	setB := NewRoaringSet(10).Add(1, 2, 3)
+	setC := NewRoaringSet(10).Add(3, 4, 5)
+
+	specs.Expect(setA.SubsetOf(setB), true)
+	specs.Expect(setA.SubsetOf(setC), false)
+}
+
+func TestRoaringSetSupersetOf(t *testing.T) {
+	specs := specs.New(t)
+
+	setA := NewRoaringSet(10).Add(1, 2)
+	setB := NewRoaringSet(10).Add(1, 2, 3)
+	setC := NewRoaringSet(10).Add(3, 4, 5)
+
+	specs.Expect(setB.SupersetOf(setA), true)
+	specs.Expect(setC.SupersetOf(setA), false)
+}
+
+func TestRoaringSetUnion(t *testing.T) {
+	specs := specs.New(t)
+
+	setA := NewRoaringSet(10).Add(1, 2)
+	setB := NewRoaringSet(10).Add(3, 4)
+	setC := NewRoaringSet(10).Add(1, 99)
+
+	specs.Expect(setA.Union(setB).Equal(NewRoaringSet(10).Add(1, 2, 3, 4)), true)
+	specs.Expect(setA.Union(setC).Equal(NewRoaringSet(10).Add(1, 2, 99)), true)
+}
+
+func TestRoaringSetIntersection(t *testing.T) {
+	specs := specs.New(t)
+
+	setA := NewRoaringSet(10).Add(1, 2)
+	setB := NewRoaringSet(10).Add(1, 2, 3)
+	setC := NewRoaringSet(10).Add(3, 4, 5)
+
+	specs.Expect(setA.Intersection(setB).Equal(setA), true)
+	specs.Expect(setB.Intersection(setC).Equal(NewRoaringSet(10).Add(3)), true)
+}
+
+func TestRoaringSetSymetricDifference(t *testing.T) {
+	specs := specs.New(t)
+
+	setA := NewRoaringSet(10).Add(1, 2, 4)
+	setB := NewRoaringSet(10).Add(1, 2, 3)
+	setC := NewRoaringSet(10).Add(3, 4, 5)
+
+	specs.Expect(setA.SymetricDifference(setB).Equal(NewRoaringSet(10).Add(3, 4)), true)
+	specs.Expect(setB.SymetricDifference(setC).Equal(NewRoaringSet(10).Add(1, 2, 4, 5)), true)
+}
+
+func TestRoaringSetClone(t *testing.T) {
+	specs := specs.New(t)
+
+	setA := NewRoaringSet(10).Add(9, 3, 1)
+	setB := setA.Clone()
+
+	specs.Expect(setB.Equal(setA), true)
+}
+
+func TestRoaringSetInPlace(t *testing.T) {
+	specs := specs.New(t)
+
+	a := NewRoaringSet(20).Add(1, 2, 4, 10)
+	for _, b := range []IntSet{NewRoaringSet(20).Add(2, 3, 10, 15), NewHashSet(20).Add(2, 3, 10, 15)} {
+		specs.Expect(a.Clone().UnionWith(b).Equal(a.Union(b)), true)
+		specs.Expect(a.Clone().IntersectWith(b).Equal(a.Intersection(b)), true)
+		specs.Expect(a.Clone().DifferenceWith(b).Equal(a.Difference(b)), true)
+		specs.Expect(a.Clone().SymmetricDifferenceWith(b).Equal(a.SymetricDifference(b)), true)
+	}
+
+	c := a.Clone()
+	specs.Expect(c.UnionWith(c).Equal(a), true)
+	specs.Expect(c.IntersectWith(c).Equal(a), true)
+	specs.Expect(c.Clone().DifferenceWith(c).Size(), 0)
+	specs.Expect(c.SymmetricDifferenceWith(c).Size(), 0)
+}
+
+func TestRoaringSetContainers(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewRoaringSet(0)
+	for i := 0; i < 5000; i++ {
+		set.Add(i * 2)
+	}
+	_, isBitmap := set.containers[0].(*bitmapContainer)
+	specs.Expect(isBitmap, true)
+	specs.Expect(set.Size(), 5000)
+
+	set.Remove(0, 2, 4, 6, 8, 10, 12, 14, 16, 18, 20, 22, 24, 26, 28, 30)
+	for i := 2500; i < 5000; i++ {
+		set.Remove(i * 2)
+	}
+	_, isArray := set.containers[0].(arrayContainer)
+	specs.Expect(isArray, true)
+	specs.Expect(set.Size(), 2500-16)
+
+	set.Clear().Add(1<<32-1, 1<<16, 3)
+	for i := 100000; i < 200000; i++ {
+		set.Add(i)
+	}
+	set.RunOptimize()
+	_, isRun := set.containers[1].(runContainer)
+	specs.Expect(isRun, true)
+	specs.Expect(set.Size(), 100003)
+	specs.Expect(set.Contains(1<<32-1, 1<<16, 3, 100000, 150000, 199999), true)
+	specs.Expect(set.Contains(99999, 200000, -1, 1<<32), false)
+
+	set.Remove(150000)
+	specs.Expect(set.Contains(149999, 150000, 150001), false)
+	specs.Expect(set.Contains(149999, 150001), true)
+	set.Add(150000)
+	specs.Expect(set.containers[2], container(runContainer{{0, 1<<16 - 1}}))
+}
+
+func TestRoaringSetAddOutOfRange(t *testing.T) {
+	specs := specs.New(t)
+
+	defer func() {
+		specs.Expect(recover() != nil, true)
+	}()
+	NewRoaringSet(0).Add(-1)
+}
+
+func TestRoaringSetAlgebra(t *testing.T) {
+	specs := specs.New(t)
+
+	r := rand.New(rand.NewSource(1))
+	fill := func(n, max int, runs bool) (*RoaringSet, *HashSet[int]) {
+		a, b := NewRoaringSet(0), NewHashSet(0)
+		for i := 0; i < n; i++ {
+			x := r.Intn(max)
+			a.Add(x)
+			b.Add(x)
+			if runs {
+				for j := 0; j < 100; j++ {
+					a.Add(x + j)
+					b.Add(x + j)
+				}
+			}
+		}
+		if runs {
+			a.RunOptimize()
+		}
+		return a, b
+	}
+
+	sets := make([]*RoaringSet, 0, 4)
+	refs := make([]*HashSet[int], 0, 4)
+	for _, args := range []struct {
+		n, max int
+		runs   bool
+	}{{100, 300000, false}, {30000, 300000, false}, {200, 300000, true}, {0, 1, false}} {
+		a, b := fill(args.n, args.max, args.runs)
+		sets = append(sets, a)
+		refs = append(refs, b)
+	}
+
+	for i, a := range sets {
+		for j, b := range sets {
+			specs.Expect(a.Union(b).Equal(refs[i].Union(refs[j])), true)
+			specs.Expect(a.Intersection(b).Equal(refs[i].Intersection(refs[j])), true)
+			specs.Expect(a.Difference(b).Equal(refs[i].Difference(refs[j])), true)
+			specs.Expect(a.SymetricDifference(b).Equal(refs[i].SymetricDifference(refs[j])), true)
+			specs.Expect(a.SubsetOf(b), refs[i].SubsetOf(refs[j]))
+			specs.Expect(a.Equal(b), i == j)
+		}
+	}
+}
+
+func TestRoaringSetNavigation(t *testing.T) {
+	specs := specs.New(t)
+
+	// an array, a bitmap and a run container
+	set := NewRoaringSet(0).Add(7, 1<<16+5)
+	for i := 2 << 16; i < 2<<16+5000; i += 2 {
+		set.Add(i)
+	}
+	for i := 3 << 16; i < 3<<16+100; i++ {
+		set.Add(i)
+	}
+	set.RunOptimize()
+
+	next := func(i int) int {
+		v, ok := set.NextAfter(i)
+		specs.Expect(ok, true)
+		return v
+	}
+	prev := func(i int) int {
+		v, ok := set.PrevBefore(i)
+		specs.Expect(ok, true)
+		return v
+	}
+	specs.Expect(next(7), 1<<16+5)
+	specs.Expect(next(1<<16+5), 2<<16)
+	specs.Expect(next(2<<16+1), 2<<16+2)
+	specs.Expect(next(2<<16+4998), 3<<16)
+	specs.Expect(next(3<<16+50), 3<<16+51)
+	specs.Expect(prev(3<<16), 2<<16+4998)
+	specs.Expect(prev(2<<16), 1<<16+5)
+	specs.Expect(prev(2<<16+3), 2<<16+2)
+	specs.Expect(prev(1<<16), 7)
+
+	max, ok := set.Max()
+	specs.Expect(max, 3<<16+99)
+	specs.Expect(ok, true)
+	_, ok = set.NextAfter(max)
+	specs.Expect(ok, false)
+}
+
+func TestRoaringSetCounts(t *testing.T) {
+	specs := specs.New(t)
+
+	// bitmap, array and run containers
+	a := NewRoaringSet(0).AddRange(0, 1<<16+100)
+	b := NewRoaringSet(0)
+	for i := 0; i < 3<<16; i += 3 {
+		b.Add(i)
+	}
+	c := NewRoaringSet(0).Add(1, 3, 1<<16+3, 5<<16)
+
+	for _, pair := range [][2]*RoaringSet{{a, b}, {b, a}, {a, c}, {b, c}, {c, b}} {
+		x, y := pair[0], pair[1]
+		specs.Expect(x.IntersectionCount(y), x.Intersection(y).Size())
+		specs.Expect(x.UnionCount(y), x.Union(y).Size())
+		specs.Expect(x.DifferenceCount(y), x.Difference(y).Size())
+		specs.Expect(x.SymmetricDifferenceCount(y), x.SymetricDifference(y).Size())
+		specs.Expect(x.Intersects(y), true)
+	}
+	specs.Expect(c.Intersects(NewRoaringSet(0).Add(2, 6<<16)), false)
+}
+
+// Benchmarks
+
+func BenchmarkRoaringSetAdd(b *testing.B) {
+	set := NewRoaringSet(1000)
+	for i := 0; i < b.N; i++ {
+		set.Add(rand.Intn(1000))
+	}
+}
+
+func BenchmarkRoaringSetRemove(b *testing.B) {
+	set := NewRoaringSet(1000)
+	for i := 0; i < 500; i++ {
+		set.Add(rand.Intn(1000))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		set.Remove(rand.Intn(1000))
+	}
+}
+
+func BenchmarkRoaringSetContains(b *testing.B) {
+	set := NewRoaringSet(1000)
+	for i := 0; i < 500; i++ {
+		set.Add(rand.Intn(1000))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		set.Contains(rand.Intn(1000))
+	}
+}
+
+func BenchmarkRoaringSetClear(b *testing.B) {
+	set := NewRoaringSet(1000)
+	for i := 0; i < 500; i++ {
+		set.Add(rand.Intn(1000))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		set.Clear()
+	}
+}
+
+func BenchmarkRoaringSetEqual(b *testing.B) {
+	setA := NewRoaringSet(100).Add(1, 3, 7, 88)
+	setB := NewRoaringSet(100).Add(88, 3, 7, 1)
+	setC := NewRoaringSet(100).Add(1, 3, 7, 89)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		setA.Equal(setB)
+		setA.Equal(setC)
+	}
+}
+
+func BenchmarkRoaringSetBigEqual(b *testing.B) {
+	setA := NewRoaringSet(10000).Add(1, 3, 700, 8888)
+	setB := NewRoaringSet(10000).Add(8888, 3, 700, 1)
+	setC := NewRoaringSet(10000).Add(1, 3, 700, 8889)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		setA.Equal(setB)
+		setA.Equal(setC)
+	}
+}
+
+func BenchmarkRoaringSetBigSubsetOf(b *testing.B) {
+	setA := NewRoaringSet(10000).Add(3, 700, 8888)
+	setB := NewRoaringSet(10000).Add(8888, 3, 700, 1)
+	setC := NewRoaringSet(10000).Add(1, 3, 700, 8889)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		setA.SubsetOf(setB)
+		setA.SubsetOf(setC)
+	}
+}
+
+func BenchmarkRoaringSetUnion(b *testing.B) {
+	setA := NewRoaringSet(100).Add(1, 3, 7, 88)
+	setB := NewRoaringSet(100).Add(33, 44, 7, 1)
+	setC := NewRoaringSet(100).Add(13, 3, 7, 89)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = setA.Union(setB).Union(setC)
+	}
+}
+
+func BenchmarkRoaringSetBigUnion(b *testing.B) {
+	setA := NewRoaringSet(10000)
+	setB := NewRoaringSet(10000)
+	for i := 0; i < 5000; i++ {
+		setA.Add(rand.Intn(10000))
+		setB.Add(rand.Intn(10000))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = setA.Union(setB)
+	}
+}
+
+func BenchmarkRoaringSetIntersection(b *testing.B) {
+	setA := NewRoaringSet(100).Add(1, 3, 7, 88)
+	setB := NewRoaringSet(100).Add(33, 44, 7, 1)
+	setC := NewRoaringSet(100).Add(13, 3, 7, 89)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = setA.Intersection(setB).Intersection(setC)
+	}
+}
+
+func BenchmarkRoaringSetSymetricDifference(b *testing.B) {
+	setA := NewRoaringSet(100).Add(1, 3, 7, 88)
+	setB := NewRoaringSet(100).Add(33, 44, 7, 1)
+	setC := NewRoaringSet(100).Add(13, 3, 27, 89)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = setA.SymetricDifference(setB).SymetricDifference(setC)
+	}
+}
+
+func BenchmarkRoaringSetBigSymetricDifference(b *testing.B) {
+	setA := NewRoaringSet(10000)
+	setB := NewRoaringSet(10000)
+	setC := NewRoaringSet(10000)
+	for i := 0; i < 5000; i++ {
+		setA.Add(rand.Intn(10000))
+		setB.Add(rand.Intn(10000))
+		setC.Add(rand.Intn(10000))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = setA.SymetricDifference(setB).SymetricDifference(setC)
+	}
+}
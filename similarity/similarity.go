@@ -0,0 +1,80 @@
+// Package similarity implements similarity coefficients between integer sets.
+//
+// The coefficients are computed from the cardinalities of the sets and of
+// their intersection, which all the set types provide without building
+// intermediate sets, so the functions allocate nothing. The sets may be of
+// different types; when they are of the same type the intersection is counted
+// natively, e.g. with popcount over the ANDed words of two BitSets.
+//
+// All coefficients range from 0, for sets with no integers in common, to 1,
+// for equal sets. If either set is empty the coefficient is 0, unless both
+// are, in which case it is 1.
+package similarity
+
+import (
+	"math"
+
+	"github.com/knakk/intset"
+)
+
+// Jaccard returns the Jaccard index of two sets: the size of their
+// intersection divided by the size of their union.
+func Jaccard[T intset.Integer](a, b intset.Set[T]) float64 {
+	i, na, nb, ok := counts(a, b)
+	if !ok {
+		return emptyValue(na, nb)
+	}
+	return float64(i) / float64(na+nb-i)
+}
+
+// SorensenDice returns the Sørensen–Dice coefficient of two sets: twice the
+// size of their intersection divided by the sum of their sizes.
+func SorensenDice[T intset.Integer](a, b intset.Set[T]) float64 {
+	i, na, nb, ok := counts(a, b)
+	if !ok {
+		return emptyValue(na, nb)
+	}
+	return 2 * float64(i) / float64(na+nb)
+}
+
+// OverlapCoefficient returns the overlap coefficient, or Szymkiewicz–Simpson
+// coefficient, of two sets: the size of their intersection divided by the size
+// of the smaller set. It is 1 if one set is a subset of the other.
+func OverlapCoefficient[T intset.Integer](a, b intset.Set[T]) float64 {
+	i, na, nb, ok := counts(a, b)
+	if !ok {
+		return emptyValue(na, nb)
+	}
+	return float64(i) / float64(min(na, nb))
+}
+
+// Cosine returns the cosine similarity, or Otsuka–Ochiai coefficient, of two
+// sets: the size of their intersection divided by the geometric mean of their
+// sizes.
+func Cosine[T intset.Integer](a, b intset.Set[T]) float64 {
+	i, na, nb, ok := counts(a, b)
+	if !ok {
+		return emptyValue(na, nb)
+	}
+	return float64(i) / math.Sqrt(float64(na)*float64(nb))
+}
+
+// counts returns the size of the intersection of a and b, and the sizes of a
+// and b. The last return value is false if either set is empty, in which case
+// the intersection is not counted.
+func counts[T intset.Integer](a, b intset.Set[T]) (int, int, int, bool) {
+	na, nb := a.Size(), b.Size()
+	if na == 0 || nb == 0 {
+		return 0, na, nb, false
+	}
+	return a.IntersectionCount(b), na, nb, true
+}
+
+// emptyValue is the value of all coefficients when a set of size na or nb is
+// empty.
+func emptyValue(na, nb int) float64 {
+	if na == 0 && nb == 0 {
+		return 1
+	}
+	return 0
+}
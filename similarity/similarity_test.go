@@ -0,0 +1,93 @@
+package similarity
+
+import (
+	"math"
+	"testing"
+
+	"github.com/knakk/intset"
+	"github.com/knakk/specs"
+)
+
+func round(f float64) float64 {
+	return math.Round(f*1e6) / 1e6
+}
+
+func TestCoefficients(t *testing.T) {
+	specs := specs.New(t)
+
+	sets := []func(max int) intset.IntSet{
+		func(max int) intset.IntSet { return intset.NewBitSet(max) },
+		func(max int) intset.IntSet { return intset.NewHashSet(max) },
+		func(max int) intset.IntSet { return intset.NewSliceSet(max) },
+	}
+	for _, fa := range sets {
+		for _, fb := range sets {
+			a, b := fa(100), fb(100)
+			a.Insert(1, 2, 3, 4)
+			b.Insert(3, 4, 5, 6, 7, 8, 9, 10, 11)
+
+			specs.Expect(round(Jaccard(a, b)), round(2.0/11))
+			specs.Expect(round(SorensenDice(a, b)), round(4.0/13))
+			specs.Expect(round(OverlapCoefficient(a, b)), 0.5)
+			specs.Expect(round(Cosine(a, b)), round(2.0/6))
+
+			specs.Expect(Jaccard(a, a), 1.0)
+			specs.Expect(SorensenDice(b, b), 1.0)
+			specs.Expect(round(Cosine(a, a)), 1.0)
+		}
+	}
+}
+
+func TestCoefficientsEmpty(t *testing.T) {
+	specs := specs.New(t)
+
+	a := intset.NewBitSet(10).Add(1, 2)
+	empty := intset.NewBitSet(10)
+	for _, f := range []func(a, b intset.IntSet) float64{
+		Jaccard[int], SorensenDice[int], OverlapCoefficient[int], Cosine[int],
+	} {
+		specs.Expect(f(a, empty), 0.0)
+		specs.Expect(f(empty, a), 0.0)
+		specs.Expect(f(empty, empty), 1.0)
+		specs.Expect(f(a, intset.NewBitSet(10).Add(3)), 0.0)
+	}
+	specs.Expect(OverlapCoefficient[int](a, intset.NewBitSet(10).Add(1, 2, 3)), 1.0)
+}
+
+func TestCoefficientsAllocations(t *testing.T) {
+	specs := specs.New(t)
+
+	pairs := [][2]intset.IntSet{
+		{intset.NewBitSet(0), intset.NewBitSet(0)},
+		{intset.NewHashSet(0), intset.NewArraySet(0)},
+		{intset.NewIntervalSet(0), intset.NewSliceSet(0)},
+		{intset.NewRoaringSet(0), intset.NewBriggsSet(0)},
+	}
+	for _, p := range pairs {
+		a, b := p[0], p[1]
+		for i := 0; i <= 1000; i++ {
+			a.Insert(i)
+		}
+		for i := 500; i <= 2000; i++ {
+			b.Insert(i)
+		}
+		allocs := testing.AllocsPerRun(10, func() {
+			Jaccard(a, b)
+			SorensenDice(a, b)
+			OverlapCoefficient(a, b)
+			Cosine(a, b)
+		})
+		specs.Expect(allocs, 0.0)
+		specs.Expect(round(Jaccard(a, b)), round(501.0/2001))
+	}
+}
+
+// Benchmarks
+
+func BenchmarkJaccardBitSet(b *testing.B) {
+	x := intset.NewBitSet(0).AddRange(0, 100000)
+	y := intset.NewBitSet(0).AddRange(50000, 200000)
+	for i := 0; i < b.N; i++ {
+		Jaccard[int](x, y)
+	}
+}
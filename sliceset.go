@@ -2,88 +2,412 @@ package intset
 
 import (
 	"fmt"
+	"iter"
+	"sort"
 	"strings"
 )
 
-// SliceSet is an integer set backed by a slice.
-type SliceSet struct {
+// rankBlockSize is the number of integers in each block of the rank index of
+// a SliceSet.
+const rankBlockSize = 512
+
+// SliceSet is an integer set backed by a slice. The slice initially holds the
+// integers from the min to the max given to the constructor, or from 0 to max
+// for NewSliceSet, and grows when integers outside that range are added,
+// unless the set is limited with Limit. Adding an integer more than 2^40
+// integers away from the others panics.
+type SliceSet[T Integer] struct {
 	data  []bool
+	base  int // the integer at data[0]
 	count int
+	limit limit
+
+	// ranks holds the number of integers before each block of data. It is
+	// built by Rank and Select, and reset when the set is modified.
+	ranks rankIndex
+}
+
+// NewSliceSet is the constructor for a SliceSet of ints.
+func NewSliceSet(max int) *SliceSet[int] {
+	return NewSliceSetOf[int](max)
 }
 
-// NewSliceSet is the constructor for SliceSet.
-func NewSliceSet(max int) *SliceSet {
-	return new(SliceSet).init(max)
+// NewSliceSetOf is the constructor for a SliceSet of any integer type.
+func NewSliceSetOf[T Integer](max int) *SliceSet[T] {
+	return new(SliceSet[T]).init(0, max)
 }
 
-func (set *SliceSet) init(max int) *SliceSet {
-	set.data = make([]bool, max+1)
+// NewSliceSetRange is the constructor for a SliceSet of ints which can hold
+// the integers from min to max, inclusive.
+func NewSliceSetRange(min, max int) *SliceSet[int] {
+	return NewSliceSetRangeOf[int](min, max)
+}
+
+// NewSliceSetRangeOf is the constructor for a SliceSet of any integer type
+// which can hold the integers from min to max, inclusive.
+func NewSliceSetRangeOf[T Integer](min, max int) *SliceSet[T] {
+	return new(SliceSet[T]).init(min, max)
+}
+
+func (set *SliceSet[T]) init(min, max int) *SliceSet[T] {
+	set.data = make([]bool, max-min+1)
+	set.base = min
+	set.count = 0
+	set.ranks.reset()
+	return set
+}
+
+// Limit restricts the set to the integers from lo to hi, inclusive. Adding an
+// integer outside the limit panics, instead of growing the set to hold it,
+// while TryAdd returns an error. Limit panics if the set already holds an
+// integer outside the limit.
+func (set *SliceSet[T]) Limit(lo, hi int) *SliceSet[T] {
+	if m, ok := set.Min(); ok && int(m) < lo {
+		panic(fmt.Sprintf("intset: %v is out of range for SliceSet", m))
+	}
+	if m, ok := set.Max(); ok && int(m) > hi {
+		panic(fmt.Sprintf("intset: %v is out of range for SliceSet", m))
+	}
+	// keep only the part of the storage within the limit
+	i, j := max(lo, set.base), min(hi, set.base+len(set.data)-1)
+	if i > j {
+		set.data, set.base = nil, lo
+	} else {
+		set.data, set.base = set.data[i-set.base:j-set.base+1], i
+	}
+	set.limit = limit{lo, hi, true}
+	set.ranks.reset()
 	return set
 }
 
 // Clear the set.
-func (set *SliceSet) Clear() *SliceSet {
+func (set *SliceSet[T]) Clear() *SliceSet[T] {
 	set.data = make([]bool, len(set.data))
 	set.count = 0
+	set.ranks.reset()
 	return set
 }
 
 // Size returns the number of integers in the set.
-func (set *SliceSet) Size() int {
+func (set *SliceSet[T]) Size() int {
 	return set.count
 }
 
-// Add one or more integers to the set.
-func (set *SliceSet) Add(ints ...int) *SliceSet {
-	for _, i := range ints {
+// Add one or more integers to the set, growing the set to hold them if
+// needed. It panics if an integer is outside the limit of the set.
+func (set *SliceSet[T]) Add(ints ...T) *SliceSet[T] {
+	for _, v := range ints {
+		i, ok := set.pos(v)
+		if !ok {
+			i = checkLimit(set.limit, "SliceSet", v)
+			set.grow(i, i)
+			i -= set.base
+		}
 		if !set.data[i] {
 			set.count++
 			set.data[i] = true
+			set.ranks.reset()
 		}
 	}
 	return set
 }
 
 // Remove one or more integers from the set.
-func (set *SliceSet) Remove(ints ...int) *SliceSet {
-	for _, i := range ints {
-		if set.data[i] == true {
+func (set *SliceSet[T]) Remove(ints ...T) *SliceSet[T] {
+	for _, v := range ints {
+		if i, ok := set.pos(v); ok && set.data[i] {
 			set.count--
+			set.data[i] = false
+			set.ranks.reset()
 		}
-		set.data[i] = false
+	}
+	return set
+}
+
+// TryAdd adds one or more integers to the set, like Add, but returns an
+// *OutOfRangeError instead of panicking if an integer is outside the limit of
+// the set. The set is left unchanged in that case.
+func (set *SliceSet[T]) TryAdd(ints ...T) error {
+	if err := checkLimits(set.limit, ints); err != nil {
+		return err
+	}
+	set.Add(ints...)
+	return nil
+}
+
+// TryRemove removes one or more integers from the set, like Remove, but
+// returns an *OutOfRangeError if an integer is outside the limit of the set.
+// The set is left unchanged in that case.
+func (set *SliceSet[T]) TryRemove(ints ...T) error {
+	if err := checkLimits(set.limit, ints); err != nil {
+		return err
+	}
+	set.Remove(ints...)
+	return nil
+}
 
+// AddRange adds the integers from lo to hi, inclusive, to the set, growing the
+// set to hold them if needed. It panics if lo or hi is outside the limit of
+// the set.
+func (set *SliceSet[T]) AddRange(lo, hi T) *SliceSet[T] {
+	i, j := set.checkRange(lo, hi)
+	if i > j {
+		return set
 	}
+	s := set.data[i : j+1]
+	set.count += len(s) - countTrue(s)
+	// fill by doubling the filled prefix
+	s[0] = true
+	for n := 1; n < len(s); n *= 2 {
+		copy(s[n:], s[:n])
+	}
+	set.ranks.reset()
 	return set
 }
 
+// RemoveRange removes the integers from lo to hi, inclusive, from the set.
+func (set *SliceSet[T]) RemoveRange(lo, hi T) *SliceSet[T] {
+	i, j, ok := clampRange(lo, hi, set.base, len(set.data))
+	if !ok {
+		return set
+	}
+	s := set.data[i : j+1]
+	set.count -= countTrue(s)
+	clear(s)
+	set.ranks.reset()
+	return set
+}
+
+// FlipRange adds the integers from lo to hi, inclusive, which are not in the
+// set, and removes those which are, growing the set to hold them if needed. It
+// panics if lo or hi is outside the limit of the set.
+func (set *SliceSet[T]) FlipRange(lo, hi T) *SliceSet[T] {
+	i, j := set.checkRange(lo, hi)
+	if i > j {
+		return set
+	}
+	s := set.data[i : j+1]
+	set.count += len(s) - 2*countTrue(s)
+	for k := range s {
+		s[k] = !s[k]
+	}
+	set.ranks.reset()
+	return set
+}
+
+// checkRange returns the positions of lo and hi, growing the set to hold them
+// if needed. It panics if lo or hi is outside the limit of the set.
+func (set *SliceSet[T]) checkRange(lo, hi T) (int, int) {
+	i := checkLimit(set.limit, "SliceSet", lo)
+	j := checkLimit(set.limit, "SliceSet", hi)
+	if i <= j {
+		set.grow(i, j)
+	}
+	return i - set.base, j - set.base
+}
+
+// pos returns the position of v in data. The second return value is false if
+// v is outside the range of the set.
+func (set *SliceSet[T]) pos(v T) (int, bool) {
+	return offset(v, set.base, len(set.data))
+}
+
+// countTrue returns the number of true values in s.
+func countTrue(s []bool) int {
+	var n int
+	for _, b := range s {
+		if b {
+			n++
+		}
+	}
+	return n
+}
+
 // All returns a slice of all the integers in the set, in ascending order.
-func (set *SliceSet) All() []int {
-	var all []int
+func (set *SliceSet[T]) All() []T {
+	var all []T
 	for i, b := range set.data {
 		if b {
-			all = append(all, i)
+			all = append(all, T(set.base+i))
 		}
 	}
 	return all
 }
 
+// Values returns an iterator over the integers in the set in ascending order.
+func (set *SliceSet[T]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i, b := range set.data {
+			if b && !yield(T(set.base+i)) {
+				return
+			}
+		}
+	}
+}
+
+// Backward returns an iterator over the integers in the set in descending
+// order.
+func (set *SliceSet[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := len(set.data) - 1; i >= 0; i-- {
+			if set.data[i] && !yield(T(set.base+i)) {
+				return
+			}
+		}
+	}
+}
+
+// Min returns the smallest integer in the set. The second return value is
+// false if the set is empty.
+func (set *SliceSet[T]) Min() (T, bool) {
+	return set.scan(0, 1)
+}
+
+// Max returns the largest integer in the set. The second return value is false
+// if the set is empty.
+func (set *SliceSet[T]) Max() (T, bool) {
+	return set.scan(len(set.data)-1, -1)
+}
+
+// NextAfter returns the smallest integer in the set which is greater than v.
+// The second return value is false if there is no such integer.
+func (set *SliceSet[T]) NextAfter(v T) (T, bool) {
+	i, ok := toInt(v)
+	switch {
+	case !ok:
+		return 0, false
+	case i < set.base:
+		return set.Min()
+	}
+	p := uint(i) - uint(set.base)
+	if p >= uint(len(set.data)) {
+		return 0, false
+	}
+	return set.scan(int(p)+1, 1)
+}
+
+// PrevBefore returns the largest integer in the set which is less than v. The
+// second return value is false if there is no such integer.
+func (set *SliceSet[T]) PrevBefore(v T) (T, bool) {
+	i, ok := toInt(v)
+	switch {
+	case !ok:
+		return set.Max()
+	case i <= set.base:
+		return 0, false
+	}
+	p := uint(i) - uint(set.base)
+	if p > uint(len(set.data)) {
+		p = uint(len(set.data))
+	}
+	return set.scan(int(p)-1, -1)
+}
+
+// scan returns the first integer in the set found by stepping from i in the
+// given direction.
+func (set *SliceSet[T]) scan(i, step int) (T, bool) {
+	if set.count == 0 {
+		return 0, false
+	}
+	for ; i >= 0 && i < len(set.data); i += step {
+		if set.data[i] {
+			return T(set.base + i), true
+		}
+	}
+	return 0, false
+}
+
 // Contains returns true if all ints are in the set, otherwise false.
-func (set *SliceSet) Contains(ints ...int) bool {
-	for _, i := range ints {
-		if !set.data[i] {
+func (set *SliceSet[T]) Contains(ints ...T) bool {
+	for _, v := range ints {
+		if i, ok := set.pos(v); !ok || !set.data[i] {
 			return false
 		}
 	}
 	return true
 }
 
+// buildRanks builds the rank index: the number of integers before each block
+// of data.
+func (set *SliceSet[T]) buildRanks() []int {
+	ranks := make([]int, (len(set.data)+rankBlockSize-1)/rankBlockSize)
+	var n int
+	for i, b := range set.data {
+		if i%rankBlockSize == 0 {
+			ranks[i/rankBlockSize] = n
+		}
+		if b {
+			n++
+		}
+	}
+	return ranks
+}
+
+// Rank returns the number of integers in the set which are less than or equal
+// to v.
+//
+// Rank and Select build an index which is reset when the set is modified, so
+// they are fastest when there are many queries between modifications. The
+// index is built safely by concurrent readers.
+func (set *SliceSet[T]) Rank(v T) int {
+	i, ok := toInt(v)
+	switch {
+	case !ok:
+		return set.count
+	case i < set.base:
+		return 0
+	}
+	p := uint(i) - uint(set.base)
+	if p >= uint(len(set.data)) {
+		return set.count
+	}
+	i = int(p)
+	n := set.ranks.get(set.buildRanks)[i/rankBlockSize]
+	for _, b := range set.data[i-i%rankBlockSize : i+1] {
+		if b {
+			n++
+		}
+	}
+	return n
+}
+
+// Select returns the k-th smallest integer in the set, counting from 0. The
+// second return value is false if k is not less than the size of the set.
+func (set *SliceSet[T]) Select(k int) (T, bool) {
+	if k < 0 || k >= set.count {
+		return 0, false
+	}
+	ranks := set.ranks.get(set.buildRanks)
+	b := sort.Search(len(ranks), func(b int) bool { return ranks[b] > k }) - 1
+	k -= ranks[b]
+	for i := b * rankBlockSize; i < len(set.data); i++ {
+		if !set.data[i] {
+			continue
+		}
+		if k == 0 {
+			return T(set.base + i), true
+		}
+		k--
+	}
+	return 0, false
+}
+
+// TryContains returns true if all ints are in the set, like Contains, but
+// returns an *OutOfRangeError if an integer is outside the limit of the set.
+func (set *SliceSet[T]) TryContains(ints ...T) (bool, error) {
+	if err := checkLimits(set.limit, ints); err != nil {
+		return false, err
+	}
+	return set.Contains(ints...), nil
+}
+
 // Equal checks if two sets both contains all the same items.
-func (set *SliceSet) Equal(other *SliceSet) bool {
+func (set *SliceSet[T]) Equal(other Set[T]) bool {
 	if set.Size() != other.Size() {
 		return false
 	}
 	for i, b := range set.data {
-		if b && !other.Contains(i) {
+		if b && !other.Contains(T(set.base+i)) {
 			return false
 		}
 	}
@@ -91,9 +415,9 @@ func (set *SliceSet) Equal(other *SliceSet) bool {
 }
 
 // SubsetOf checks if all items in set are also present in other set.
-func (set *SliceSet) SubsetOf(other *SliceSet) bool {
+func (set *SliceSet[T]) SubsetOf(other Set[T]) bool {
 	for i, b := range set.data {
-		if b && !other.Contains(i) {
+		if b && !other.Contains(T(set.base+i)) {
 			return false
 		}
 	}
@@ -101,85 +425,326 @@ func (set *SliceSet) SubsetOf(other *SliceSet) bool {
 }
 
 // SupersetOf checks if a set is a superset of another set.
-func (set *SliceSet) SupersetOf(other *SliceSet) bool {
+func (set *SliceSet[T]) SupersetOf(other Set[T]) bool {
 	return other.SubsetOf(set)
 }
 
 // Union returns a new set which is the union of two sets.
-func (set *SliceSet) Union(other *SliceSet) *SliceSet {
-	result := NewSliceSet(max(len(set.data), len(other.data)))
-	for i, b := range set.data {
-		if b {
-			result.Add(i)
-		}
-	}
-	for i, b := range other.data {
-		if b {
-			result.Add(i)
-		}
-	}
-	return result
+func (set *SliceSet[T]) Union(other Set[T]) *SliceSet[T] {
+	return set.Clone().UnionWith(other)
 }
 
 // Intersection returns a new set with integers common to both sets.
-func (set *SliceSet) Intersection(other *SliceSet) *SliceSet {
-	result := &SliceSet{}
+func (set *SliceSet[T]) Intersection(other Set[T]) *SliceSet[T] {
+	o, ok := other.(*SliceSet[T])
 	// always loop over the smallest set
-	if len(set.data) < len(other.data) {
-		result.init(max(len(set.data), len(other.data)))
-		for i, b := range set.data {
-			if b && other.Contains(i) {
-				result.Add(i)
-			}
-		}
-	} else {
-		result.init(max(len(set.data), len(other.data)))
-		for i, b := range other.data {
-			if b && set.Contains(i) {
-				result.Add(i)
-			}
+	if ok && len(o.data) < len(set.data) {
+		return o.Intersection(set)
+	}
+	result := set.empty()
+	for i, b := range set.data {
+		if b && other.Contains(T(set.base+i)) {
+			result.data[i] = true
+			result.count++
 		}
 	}
 	return result
 }
 
 // Difference returns a new set with the integers in set which are not in other.
-func (set *SliceSet) Difference(other *SliceSet) *SliceSet {
-	result := NewSliceSet(len(set.data))
+func (set *SliceSet[T]) Difference(other Set[T]) *SliceSet[T] {
+	result := set.empty()
 	for i, b := range set.data {
-		if b && !other.Contains(i) {
-			result.Add(i)
+		if b && !other.Contains(T(set.base+i)) {
+			result.data[i] = true
+			result.count++
 		}
 	}
 	return result
 }
 
+// empty returns a new empty set with the same range and limit as set.
+func (set *SliceSet[T]) empty() *SliceSet[T] {
+	return &SliceSet[T]{data: make([]bool, len(set.data)), base: set.base, limit: set.limit}
+}
+
 // SymetricDifference returns a new set with the integers in current and other,
 // but not in both.
-func (set *SliceSet) SymetricDifference(other *SliceSet) *SliceSet {
+func (set *SliceSet[T]) SymetricDifference(other Set[T]) *SliceSet[T] {
 	a := set.Difference(other)
-	b := other.Difference(set)
+	b := other.DifferenceSet(set)
 	return a.Union(b)
 }
 
-// Clone returns a new set which is a clone of current set.
-func (set *SliceSet) Clone() *SliceSet {
-	result := NewSliceSet(len(set.data))
+// UnionWith adds the integers in other to the set, enlarging the range of the
+// set if needed. It is the in-place variant of Union.
+func (set *SliceSet[T]) UnionWith(other Set[T]) *SliceSet[T] {
+	set.growFor(other)
+	if o, ok := other.(*SliceSet[T]); ok {
+		d := o.base - set.base
+		for i, b := range o.data {
+			if b && !set.data[d+i] {
+				set.data[d+i] = true
+				set.count++
+			}
+		}
+		set.ranks.reset()
+		return set
+	}
+	for i := range other.Values() {
+		set.Add(i)
+	}
+	return set
+}
+
+// IntersectWith removes the integers which are not in other from the set. It
+// is the in-place variant of Intersection.
+func (set *SliceSet[T]) IntersectWith(other Set[T]) *SliceSet[T] {
 	for i, b := range set.data {
-		if b {
-			result.Add(i)
+		if b && !other.Contains(T(set.base+i)) {
+			set.data[i] = false
+			set.count--
 		}
 	}
+	set.ranks.reset()
+	return set
+}
+
+// DifferenceWith removes the integers in other from the set. It is the
+// in-place variant of Difference.
+func (set *SliceSet[T]) DifferenceWith(other Set[T]) *SliceSet[T] {
+	if other.Size() < set.count {
+		for i := range other.Values() {
+			set.Remove(i)
+		}
+		return set
+	}
+	for i, b := range set.data {
+		if b && other.Contains(T(set.base+i)) {
+			set.data[i] = false
+			set.count--
+		}
+	}
+	set.ranks.reset()
+	return set
+}
+
+// SymmetricDifferenceWith adds the integers in other which are not in the set,
+// and removes those which are, enlarging the range of the set if needed. It is
+// the in-place variant of SymetricDifference.
+func (set *SliceSet[T]) SymmetricDifferenceWith(other Set[T]) *SliceSet[T] {
+	if o, ok := other.(*SliceSet[T]); ok && o == set {
+		return set.Clear()
+	}
+	set.growFor(other)
+	for i := range other.Values() {
+		if set.Contains(i) {
+			set.Remove(i)
+		} else {
+			set.Add(i)
+		}
+	}
+	return set
+}
+
+// growFor enlarges the range of the set to hold the smallest and the largest
+// integer in other. It panics if they are outside the limit of the set.
+func (set *SliceSet[T]) growFor(other Set[T]) {
+	lo, ok := other.Min()
+	if !ok {
+		return
+	}
+	hi, _ := other.Max()
+	set.grow(checkLimit(set.limit, "SliceSet", lo), checkLimit(set.limit, "SliceSet", hi))
+}
+
+// grow enlarges the range of the set to hold the integers from lo to hi, as far
+// as the limit allows.
+func (set *SliceSet[T]) grow(lo, hi int) {
+	base, n := set.limit.grown("SliceSet", set.base, len(set.data), lo, hi)
+	if base == set.base && n == len(set.data) {
+		return
+	}
+	data := make([]bool, n)
+	if len(set.data) > 0 {
+		copy(data[set.base-base:], set.data)
+	}
+	set.data, set.base = data, base
+	set.ranks.reset()
+}
+
+// overlap returns the positions in set and o of the first integer in the range
+// of both sets, and the number of integers in that shared range.
+func (set *SliceSet[T]) overlap(o *SliceSet[T]) (int, int, int) {
+	lo := max(set.base, o.base)
+	hi := min(set.base+len(set.data)-1, o.base+len(o.data)-1)
+	return lo - set.base, lo - o.base, max(hi-lo+1, 0)
+}
+
+// IntersectionCount returns the number of integers common to both sets,
+// without building the intersection.
+func (set *SliceSet[T]) IntersectionCount(other Set[T]) int {
+	o, ok := other.(*SliceSet[T])
+	if !ok {
+		return intersectionCount[T](set, other)
+	}
+	var n int
+	i, j, k := set.overlap(o)
+	for _, b := range set.data[i : i+k] {
+		if b && o.data[j] {
+			n++
+		}
+		j++
+	}
+	return n
+}
+
+// UnionCount returns the number of integers in the union of two sets, without
+// building the union.
+func (set *SliceSet[T]) UnionCount(other Set[T]) int {
+	return set.Size() + other.Size() - set.IntersectionCount(other)
+}
+
+// DifferenceCount returns the number of integers in set which are not in
+// other, without building the difference.
+func (set *SliceSet[T]) DifferenceCount(other Set[T]) int {
+	return set.Size() - set.IntersectionCount(other)
+}
+
+// SymmetricDifferenceCount returns the number of integers in current and
+// other, but not in both, without building the symmetric difference.
+func (set *SliceSet[T]) SymmetricDifferenceCount(other Set[T]) int {
+	return set.Size() + other.Size() - 2*set.IntersectionCount(other)
+}
+
+// Intersects returns true if the sets have at least one integer in common.
+func (set *SliceSet[T]) Intersects(other Set[T]) bool {
+	o, ok := other.(*SliceSet[T])
+	if !ok {
+		return intersects[T](set, other)
+	}
+	i, j, k := set.overlap(o)
+	for _, b := range set.data[i : i+k] {
+		if b && o.data[j] {
+			return true
+		}
+		j++
+	}
+	return false
+}
+
+// Clone returns a new set which is a clone of current set.
+func (set *SliceSet[T]) Clone() *SliceSet[T] {
+	result := set.empty()
+	copy(result.data, set.data)
+	result.count = set.count
 	return result
 }
 
+// Insert adds one or more integers to the set. It is the Set adapter for Add.
+func (set *SliceSet[T]) Insert(ints ...T) {
+	set.Add(ints...)
+}
+
+// Delete removes one or more integers from the set. It is the Set adapter
+// for Remove.
+func (set *SliceSet[T]) Delete(ints ...T) {
+	set.Remove(ints...)
+}
+
+// CloneSet is the Set adapter for Clone.
+func (set *SliceSet[T]) CloneSet() Set[T] {
+	return set.Clone()
+}
+
+// UnionSet is the Set adapter for Union.
+func (set *SliceSet[T]) UnionSet(other Set[T]) Set[T] {
+	return set.Union(other)
+}
+
+// IntersectionSet is the Set adapter for Intersection.
+func (set *SliceSet[T]) IntersectionSet(other Set[T]) Set[T] {
+	return set.Intersection(other)
+}
+
+// DifferenceSet is the Set adapter for Difference.
+func (set *SliceSet[T]) DifferenceSet(other Set[T]) Set[T] {
+	return set.Difference(other)
+}
+
+// SymetricDifferenceSet is the Set adapter for SymetricDifference.
+func (set *SliceSet[T]) SymetricDifferenceSet(other Set[T]) Set[T] {
+	return set.SymetricDifference(other)
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (set *SliceSet[T]) MarshalBinary() ([]byte, error) {
+	return marshalBinary(tagSliceSet, set.All()), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface. The
+// data may have been marshalled from any set type.
+func (set *SliceSet[T]) UnmarshalBinary(data []byte) error {
+	ints, err := unmarshalBinary[T](data)
+	if err != nil {
+		return err
+	}
+	return set.load(ints)
+}
+
+// MarshalJSON implements the json.Marshaler interface. The set is marshalled
+// as an array of integers in ascending order.
+func (set *SliceSet[T]) MarshalJSON() ([]byte, error) {
+	return marshalJSON(set.All()), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It accepts an array
+// of integers or a string in the form produced by MarshalText.
+func (set *SliceSet[T]) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, set.load, expanded(set.load))
+}
+
+// MarshalText implements the encoding.TextMarshaler interface. The set is
+// marshalled as a comma separated list of integers and ranges of integers in
+// ascending order, e.g. "1-5,9".
+func (set *SliceSet[T]) MarshalText() ([]byte, error) {
+	return marshalText(set.All()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface. It accepts
+// the form produced by MarshalText as well as the one produced by String.
+func (set *SliceSet[T]) UnmarshalText(text []byte) error {
+	return unmarshalText(text, expanded(set.load))
+}
+
+// load replaces the contents of the set with the given integers. The set keeps
+// its range if it is large enough for the integers, otherwise it is enlarged.
+// It returns an error if the integers are too far apart for the set to hold.
+func (set *SliceSet[T]) load(ints []T) error {
+	if err := checkInts("SliceSet", ints); err != nil {
+		return err
+	}
+	for _, v := range ints {
+		if !set.limit.allows(int(v)) {
+			return fmt.Errorf("intset: %v is out of range for SliceSet", v)
+		}
+	}
+	lo, hi, err := denseSpan("SliceSet", set.base, set.base+len(set.data)-1, ints)
+	if err != nil {
+		return err
+	}
+	set.init(lo, hi)
+	set.Add(ints...)
+	return nil
+}
+
 // String implements the Stringer interface for SliceSet.
-func (set *SliceSet) String() string {
+func (set *SliceSet[T]) String() string {
 	items := make([]string, 0, len(set.data))
 
 	for i, b := range set.data {
 		if b {
-			items = append(items, fmt.Sprintf("%v", i))
+			items = append(items, fmt.Sprintf("%v", T(set.base+i)))
 		}
 	}
 	return fmt.Sprintf("Set{%s}", strings.Join(items, ", "))
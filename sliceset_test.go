@@ -1,6 +1,9 @@
 package intset
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"math/rand"
 	"testing"
 
@@ -25,6 +28,145 @@ func TestSliceSetRemove(t *testing.T) {
 	specs.Expect(set.Contains(1, 3), false)
 }
 
+func TestSliceSetRanges(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewSliceSet(200).AddRange(10, 140)
+	specs.Expect(set.Size(), 131)
+	specs.Expect(set.Contains(10, 63, 64, 140), true)
+	specs.Expect(set.Contains(9), false)
+	specs.Expect(set.Contains(141), false)
+
+	set.RemoveRange(20, 129)
+	specs.Expect(set.Size(), 21)
+	specs.Expect(set.Contains(19, 130), true)
+	specs.Expect(set.Contains(20), false)
+	specs.Expect(set.Contains(129), false)
+
+	set.FlipRange(15, 25)
+	specs.Expect(set.Size(), 22)
+	specs.Expect(set.Contains(14, 20, 25), true)
+	specs.Expect(set.Contains(15), false)
+	specs.Expect(set.Contains(26), false)
+
+	set.RemoveRange(-5, 1000)
+	specs.Expect(set.Size(), 0)
+	set.AddRange(5, 4)
+	specs.Expect(set.Size(), 0)
+}
+
+func TestSliceSetAddRangeOutOfRange(t *testing.T) {
+	specs := specs.New(t)
+
+	defer func() {
+		specs.Expect(recover() != nil, true)
+	}()
+	NewSliceSet(200).Limit(0, 200).AddRange(100, 201)
+}
+
+func TestSliceSetRange(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewSliceSetRange(-10, 10).Add(-10, -3, 10)
+	specs.Expect(set.All(), []int{-10, -3, 10})
+	specs.Expect(set.Contains(-11), false)
+	specs.Expect(set.Rank(-3), 2)
+	i, _ := set.Select(1)
+	specs.Expect(i, -3)
+	specs.Expect(set.String(), "Set{-10, -3, 10}")
+
+	set.UnionWith(NewSliceSetRange(-50, 0).Add(-50))
+	specs.Expect(set.All(), []int{-50, -10, -3, 10})
+	specs.Expect(NewSliceSetRange(-10, 10).Add(-10).Intersects(NewSliceSet(100).Add(10)), false)
+	specs.Expect(NewSliceSetRange(-10, 10).Add(-10).IntersectionCount(NewSliceSetRange(-20, -5).Add(-10)), 1)
+
+	specs.Expect(set.UnmarshalText([]byte("-100,20")), nil)
+	specs.Expect(set.All(), []int{-100, 20})
+
+	defer func() {
+		specs.Expect(recover() != nil, true)
+	}()
+	NewSliceSetRange(-10, 10).Limit(-10, 10).Add(-11)
+}
+
+func TestSliceSetGrow(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewSliceSet(10).Add(1, 1000, -5)
+	specs.Expect(set.Contains(1, 1000, -5), true)
+	specs.Expect(set.Contains(2000, -2000), false)
+	specs.Expect(set.Size(), 3)
+	set.AddRange(-20, -10).FlipRange(990, 1010)
+	specs.Expect(set.Size(), 33)
+	specs.Expect(set.Contains(-20, 990, 1010), true)
+	specs.Expect(set.Contains(1000), false)
+
+	var zero SliceSet[int]
+	specs.Expect(zero.Contains(3), false)
+	specs.Expect(zero.Add(3).Contains(3), true)
+
+	limited := NewSliceSet(10).Add(5).Limit(0, 100).Add(100)
+	specs.Expect(limited.Contains(5, 100), true)
+	specs.Expect(limited.UnmarshalText([]byte("101")) != nil, true)
+	specs.Expect(limited.Contains(5, 100), true)
+
+	defer func() {
+		specs.Expect(recover() != nil, true)
+	}()
+	limited.UnionWith(NewSliceSet(0).Add(101))
+}
+
+func TestSliceSetGrowBounds(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewSliceSetRange(math.MaxInt-10, math.MaxInt-5).Add(math.MaxInt-5, math.MaxInt)
+	specs.Expect(set.Contains(math.MaxInt-5, math.MaxInt), true)
+	specs.Expect(set.Size(), 2)
+	specs.Expect(set.Rank(math.MaxInt), 2)
+
+	set = NewSliceSetRange(math.MinInt+5, math.MinInt+10).Add(math.MinInt+5, math.MinInt)
+	specs.Expect(set.Contains(math.MinInt+5, math.MinInt), true)
+	specs.Expect(set.Size(), 2)
+	set.AddRange(math.MinInt, math.MinInt+20)
+	specs.Expect(set.Size(), 21)
+
+	defer func() {
+		specs.Expect(fmt.Sprint(recover()), "intset: SliceSet cannot grow to hold the integers from 0 to 9223372036854775807")
+	}()
+	NewSliceSet(0).Add(math.MaxInt)
+}
+
+func TestSliceSetTry(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewSliceSetRange(-10, 10).Limit(-10, 10)
+	specs.Expect(set.TryAdd(-10, 3, 10), nil)
+	specs.Expect(set.Contains(-10, 3, 10), true)
+
+	err := set.TryAdd(4, 11)
+	specs.Expect(errors.Is(err, ErrOutOfRange), true)
+	var rangeErr *OutOfRangeError[int]
+	specs.Expect(errors.As(err, &rangeErr), true)
+	specs.Expect(*rangeErr, OutOfRangeError[int]{Value: 11, Min: -10, Max: 10})
+	specs.Expect(err.Error(), "intset: 11 is out of range [-10, 10]")
+	specs.Expect(set.Contains(4), false)
+
+	specs.Expect(errors.Is(set.TryRemove(3, -11), ErrOutOfRange), true)
+	specs.Expect(set.Contains(3), true)
+	specs.Expect(set.TryRemove(3), nil)
+	specs.Expect(set.Contains(3), false)
+
+	ok, err := set.TryContains(-10, 10)
+	specs.Expect(ok, true)
+	specs.Expect(err, nil)
+	ok, err = set.TryContains(20)
+	specs.Expect(ok, false)
+	specs.Expect(errors.Is(err, ErrOutOfRange), true)
+
+	specs.Expect(NewSliceSet(10).TryAdd(1000), nil)
+	specs.Expect(errors.Is(NewSliceSetOf[uint64](10).TryAdd(1<<64-1), ErrOutOfRange), true)
+}
+
 func TestSliceSetContains(t *testing.T) {
 	specs := specs.New(t)
 
@@ -65,6 +207,88 @@ func TestSliceSetAll(t *testing.T) {
 	specs.Expect(all[2], 99)
 }
 
+func TestSliceSetValues(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewSliceSet(100).Add(99, 1, 5)
+	var all []int
+	for i := range set.Values() {
+		all = append(all, i)
+	}
+	specs.Expect(all, []int{1, 5, 99})
+
+	all = nil
+	for i := range set.Values() {
+		all = append(all, i)
+		if i >= 5 {
+			break
+		}
+	}
+	specs.Expect(all, []int{1, 5})
+}
+
+func TestSliceSetBackward(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewSliceSet(100).Add(99, 1, 5)
+	var all []int
+	for i := range set.Backward() {
+		all = append(all, i)
+	}
+	specs.Expect(all, []int{99, 5, 1})
+
+	all = nil
+	for i := range set.Backward() {
+		all = append(all, i)
+		if i <= 5 {
+			break
+		}
+	}
+	specs.Expect(all, []int{99, 5})
+}
+
+func TestSliceSetRank(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewSliceSet(1000).Add(99, 1, 5, 64, 1000)
+
+	specs.Expect(set.Rank(-1), 0)
+	specs.Expect(set.Rank(0), 0)
+	specs.Expect(set.Rank(1), 1)
+	specs.Expect(set.Rank(63), 2)
+	specs.Expect(set.Rank(64), 3)
+	specs.Expect(set.Rank(999), 4)
+	specs.Expect(set.Rank(1000), 5)
+	specs.Expect(set.Rank(100000), 5)
+
+	set.Remove(5)
+	specs.Expect(set.Rank(64), 2)
+}
+
+func TestSliceSetSelect(t *testing.T) {
+	specs := specs.New(t)
+
+	set := NewSliceSet(100000)
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		set.Add(r.Intn(100000))
+	}
+	for k, want := range set.All() {
+		i, ok := set.Select(k)
+		specs.Expect(ok, true)
+		specs.Expect(i, want)
+		specs.Expect(set.Rank(i), k+1)
+	}
+	_, ok := set.Select(set.Size())
+	specs.Expect(ok, false)
+	_, ok = set.Select(-1)
+	specs.Expect(ok, false)
+
+	set.Remove(set.All()[0])
+	i, _ := set.Select(0)
+	specs.Expect(i, set.All()[0])
+}
+
 func TestSliceSetEqual(t *testing.T) {
 	specs := specs.New(t)
 
@@ -140,6 +364,28 @@ func TestSliceSetClone(t *testing.T) {
 	specs.Expect(setB.Equal(setA), true)
 }
 
+func TestSliceSetInPlace(t *testing.T) {
+	specs := specs.New(t)
+
+	a := NewSliceSet(20).Add(1, 2, 4, 10)
+	for _, b := range []IntSet{NewSliceSet(20).Add(2, 3, 10, 15), NewHashSet(20).Add(2, 3, 10, 15)} {
+		specs.Expect(a.Clone().UnionWith(b).Equal(a.Union(b)), true)
+		specs.Expect(a.Clone().IntersectWith(b).Equal(a.Intersection(b)), true)
+		specs.Expect(a.Clone().DifferenceWith(b).Equal(a.Difference(b)), true)
+		specs.Expect(a.Clone().SymmetricDifferenceWith(b).Equal(a.SymetricDifference(b)), true)
+	}
+
+	c := a.Clone()
+	specs.Expect(c.UnionWith(c).Equal(a), true)
+	specs.Expect(c.IntersectWith(c).Equal(a), true)
+	specs.Expect(c.Clone().DifferenceWith(c).Size(), 0)
+	specs.Expect(c.SymmetricDifferenceWith(c).Size(), 0)
+
+	// the range of the set is enlarged to hold the other set
+	specs.Expect(NewSliceSet(5).Add(1).UnionWith(NewHashSet(0).Add(40)).Contains(1, 40), true)
+	specs.Expect(NewSliceSet(5).Add(1).SymmetricDifferenceWith(NewHashSet(0).Add(40)).Contains(1, 40), true)
+}
+
 // Benchmarks
 
 func BenchmarkSliceSetAdd(b *testing.B) {
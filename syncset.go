@@ -0,0 +1,419 @@
+package intset
+
+import (
+	"iter"
+	"sync"
+	"sync/atomic"
+)
+
+// syncSetID gives each SyncSet a unique id, which is used to order locking
+// when an operation involves two sets.
+var syncSetID atomic.Uint64
+
+// SyncSet is a set which is safe for concurrent use by multiple goroutines.
+// It wraps another set and guards it with a sync.RWMutex.
+//
+// Operations involving two SyncSets lock both of them, always in the same
+// order, so they see a consistent view of both operands and cannot deadlock.
+// Other sets passed as operands are not locked.
+type SyncSet[T Integer] struct {
+	mu   sync.RWMutex
+	id   uint64
+	data Set[T]
+}
+
+// Synchronized returns a SyncSet wrapping set. The set must not be used
+// directly afterwards.
+func Synchronized[T Integer](set Set[T]) *SyncSet[T] {
+	return &SyncSet[T]{id: syncSetID.Add(1), data: set}
+}
+
+// rlockWith read-locks both set and other, and returns a function which
+// unlocks them. Other may be nil.
+func (set *SyncSet[T]) rlockWith(other *SyncSet[T]) func() {
+	if other == nil || other == set {
+		set.mu.RLock()
+		return set.mu.RUnlock
+	}
+	a, b := set, other
+	if a.id > b.id {
+		a, b = b, a
+	}
+	a.mu.RLock()
+	b.mu.RLock()
+	return func() {
+		b.mu.RUnlock()
+		a.mu.RUnlock()
+	}
+}
+
+// lockWith locks set for writing and other for reading. Other may be nil. The
+// locks are released with unlockWith, rather than with a returned function
+// as in rlockWith, so that the in-place methods don't allocate.
+func (set *SyncSet[T]) lockWith(other *SyncSet[T]) {
+	switch {
+	case other == nil || other == set:
+		set.mu.Lock()
+	case set.id < other.id:
+		set.mu.Lock()
+		other.mu.RLock()
+	default:
+		other.mu.RLock()
+		set.mu.Lock()
+	}
+}
+
+// unlockWith releases the locks taken by lockWith.
+func (set *SyncSet[T]) unlockWith(other *SyncSet[T]) {
+	if other != nil && other != set {
+		other.mu.RUnlock()
+	}
+	set.mu.Unlock()
+}
+
+// unwrap returns the set wrapped by other if it is a SyncSet.
+func unwrap[T Integer](other Set[T]) (Set[T], *SyncSet[T]) {
+	if o, ok := other.(*SyncSet[T]); ok {
+		return o.data, o
+	}
+	return other, nil
+}
+
+// Clear the set.
+func (set *SyncSet[T]) Clear() *SyncSet[T] {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+	set.data.Delete(set.data.All()...)
+	return set
+}
+
+// Size returns the number of integers in the set.
+func (set *SyncSet[T]) Size() int {
+	set.mu.RLock()
+	defer set.mu.RUnlock()
+	return set.data.Size()
+}
+
+// Add one or more integers to the set.
+func (set *SyncSet[T]) Add(ints ...T) *SyncSet[T] {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+	set.data.Insert(ints...)
+	return set
+}
+
+// Remove one or more integers from the set.
+func (set *SyncSet[T]) Remove(ints ...T) *SyncSet[T] {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+	set.data.Delete(ints...)
+	return set
+}
+
+// AddIfAbsent adds i to the set if it is not already present. It returns true
+// if i was added.
+func (set *SyncSet[T]) AddIfAbsent(i T) bool {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+	if set.data.Contains(i) {
+		return false
+	}
+	set.data.Insert(i)
+	return true
+}
+
+// RemoveIfPresent removes i from the set if it is present. It returns true if
+// i was removed.
+func (set *SyncSet[T]) RemoveIfPresent(i T) bool {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+	if !set.data.Contains(i) {
+		return false
+	}
+	set.data.Delete(i)
+	return true
+}
+
+// Snapshot returns an unsynchronized copy of the wrapped set.
+func (set *SyncSet[T]) Snapshot() Set[T] {
+	set.mu.RLock()
+	defer set.mu.RUnlock()
+	return set.data.CloneSet()
+}
+
+// View calls fn with the wrapped set while holding a read lock, so that fn
+// can perform several reads atomically. Fn must not modify the set or retain
+// it after returning.
+func (set *SyncSet[T]) View(fn func(set Set[T])) {
+	set.mu.RLock()
+	defer set.mu.RUnlock()
+	fn(set.data)
+}
+
+// Update calls fn with the wrapped set while holding the write lock, so that
+// fn can perform several reads and writes atomically. Fn must not retain the
+// set after returning.
+func (set *SyncSet[T]) Update(fn func(set Set[T])) {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+	fn(set.data)
+}
+
+// All returns a slice of all the integers in the set.
+func (set *SyncSet[T]) All() []T {
+	set.mu.RLock()
+	defer set.mu.RUnlock()
+	return set.data.All()
+}
+
+// Values returns an iterator over the integers in the set. It iterates over a
+// copy of the integers taken when the iteration starts, so the set may be
+// modified during the iteration.
+func (set *SyncSet[T]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, i := range set.All() {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+// Min returns the smallest integer in the set. The second return value is
+// false if the set is empty.
+func (set *SyncSet[T]) Min() (T, bool) {
+	set.mu.RLock()
+	defer set.mu.RUnlock()
+	return set.data.Min()
+}
+
+// Max returns the largest integer in the set. The second return value is false
+// if the set is empty.
+func (set *SyncSet[T]) Max() (T, bool) {
+	set.mu.RLock()
+	defer set.mu.RUnlock()
+	return set.data.Max()
+}
+
+// NextAfter returns the smallest integer in the set which is greater than v.
+// The second return value is false if there is no such integer.
+func (set *SyncSet[T]) NextAfter(v T) (T, bool) {
+	set.mu.RLock()
+	defer set.mu.RUnlock()
+	return set.data.NextAfter(v)
+}
+
+// PrevBefore returns the largest integer in the set which is less than v. The
+// second return value is false if there is no such integer.
+func (set *SyncSet[T]) PrevBefore(v T) (T, bool) {
+	set.mu.RLock()
+	defer set.mu.RUnlock()
+	return set.data.PrevBefore(v)
+}
+
+// Contains returns true if all ints are in the set, otherwise false.
+func (set *SyncSet[T]) Contains(ints ...T) bool {
+	set.mu.RLock()
+	defer set.mu.RUnlock()
+	return set.data.Contains(ints...)
+}
+
+// Equal checks if two sets both contains all the same items.
+func (set *SyncSet[T]) Equal(other Set[T]) bool {
+	other, o := unwrap(other)
+	defer set.rlockWith(o)()
+	return set.data.Equal(other)
+}
+
+// SubsetOf checks if all items in set are also present in other set.
+func (set *SyncSet[T]) SubsetOf(other Set[T]) bool {
+	other, o := unwrap(other)
+	defer set.rlockWith(o)()
+	return set.data.SubsetOf(other)
+}
+
+// SupersetOf checks if a set is a superset of another set.
+func (set *SyncSet[T]) SupersetOf(other Set[T]) bool {
+	other, o := unwrap(other)
+	defer set.rlockWith(o)()
+	return set.data.SupersetOf(other)
+}
+
+// Union returns a new set which is the union of two sets.
+func (set *SyncSet[T]) Union(other Set[T]) *SyncSet[T] {
+	other, o := unwrap(other)
+	defer set.rlockWith(o)()
+	return Synchronized(set.data.UnionSet(other))
+}
+
+// Intersection returns a new set with integers common to both sets.
+func (set *SyncSet[T]) Intersection(other Set[T]) *SyncSet[T] {
+	other, o := unwrap(other)
+	defer set.rlockWith(o)()
+	return Synchronized(set.data.IntersectionSet(other))
+}
+
+// Difference returns a new set with the integers in set which are not in other.
+func (set *SyncSet[T]) Difference(other Set[T]) *SyncSet[T] {
+	other, o := unwrap(other)
+	defer set.rlockWith(o)()
+	return Synchronized(set.data.DifferenceSet(other))
+}
+
+// SymetricDifference returns a new set with the integers in current and other,
+// but not in both.
+func (set *SyncSet[T]) SymetricDifference(other Set[T]) *SyncSet[T] {
+	other, o := unwrap(other)
+	defer set.rlockWith(o)()
+	return Synchronized(set.data.SymetricDifferenceSet(other))
+}
+
+// UnionWith adds the integers in other to the set. It is the in-place variant
+// of Union.
+func (set *SyncSet[T]) UnionWith(other Set[T]) *SyncSet[T] {
+	other, o := unwrap(other)
+	set.lockWith(o)
+	defer set.unlockWith(o)
+	if !applyWith(set.data, opUnionWith, other) {
+		for i := range other.Values() {
+			set.data.Insert(i)
+		}
+	}
+	return set
+}
+
+// IntersectWith removes the integers which are not in other from the set. It
+// is the in-place variant of Intersection.
+func (set *SyncSet[T]) IntersectWith(other Set[T]) *SyncSet[T] {
+	other, o := unwrap(other)
+	set.lockWith(o)
+	defer set.unlockWith(o)
+	if !applyWith(set.data, opIntersectWith, other) {
+		for _, i := range set.data.All() {
+			if !other.Contains(i) {
+				set.data.Delete(i)
+			}
+		}
+	}
+	return set
+}
+
+// DifferenceWith removes the integers in other from the set. It is the
+// in-place variant of Difference.
+func (set *SyncSet[T]) DifferenceWith(other Set[T]) *SyncSet[T] {
+	other, o := unwrap(other)
+	set.lockWith(o)
+	defer set.unlockWith(o)
+	if !applyWith(set.data, opDifferenceWith, other) {
+		for _, i := range other.All() {
+			set.data.Delete(i)
+		}
+	}
+	return set
+}
+
+// SymmetricDifferenceWith adds the integers in other which are not in the set,
+// and removes those which are. It is the in-place variant of
+// SymetricDifference.
+func (set *SyncSet[T]) SymmetricDifferenceWith(other Set[T]) *SyncSet[T] {
+	other, o := unwrap(other)
+	set.lockWith(o)
+	defer set.unlockWith(o)
+	if !applyWith(set.data, opSymmetricDifferenceWith, other) {
+		for _, i := range other.All() {
+			if set.data.Contains(i) {
+				set.data.Delete(i)
+			} else {
+				set.data.Insert(i)
+			}
+		}
+	}
+	return set
+}
+
+// IntersectionCount returns the number of integers common to both sets,
+// without building the intersection.
+func (set *SyncSet[T]) IntersectionCount(other Set[T]) int {
+	other, o := unwrap(other)
+	defer set.rlockWith(o)()
+	return set.data.IntersectionCount(other)
+}
+
+// UnionCount returns the number of integers in the union of two sets, without
+// building the union.
+func (set *SyncSet[T]) UnionCount(other Set[T]) int {
+	other, o := unwrap(other)
+	defer set.rlockWith(o)()
+	return set.data.UnionCount(other)
+}
+
+// DifferenceCount returns the number of integers in set which are not in
+// other, without building the difference.
+func (set *SyncSet[T]) DifferenceCount(other Set[T]) int {
+	other, o := unwrap(other)
+	defer set.rlockWith(o)()
+	return set.data.DifferenceCount(other)
+}
+
+// SymmetricDifferenceCount returns the number of integers in current and
+// other, but not in both, without building the symmetric difference.
+func (set *SyncSet[T]) SymmetricDifferenceCount(other Set[T]) int {
+	other, o := unwrap(other)
+	defer set.rlockWith(o)()
+	return set.data.SymmetricDifferenceCount(other)
+}
+
+// Intersects returns true if the sets have at least one integer in common.
+func (set *SyncSet[T]) Intersects(other Set[T]) bool {
+	other, o := unwrap(other)
+	defer set.rlockWith(o)()
+	return set.data.Intersects(other)
+}
+
+// Clone returns a new set which is a clone of current set.
+func (set *SyncSet[T]) Clone() *SyncSet[T] {
+	return Synchronized(set.Snapshot())
+}
+
+// Insert adds one or more integers to the set. It is the Set adapter for Add.
+func (set *SyncSet[T]) Insert(ints ...T) {
+	set.Add(ints...)
+}
+
+// Delete removes one or more integers from the set. It is the Set adapter
+// for Remove.
+func (set *SyncSet[T]) Delete(ints ...T) {
+	set.Remove(ints...)
+}
+
+// CloneSet is the Set adapter for Clone.
+func (set *SyncSet[T]) CloneSet() Set[T] {
+	return set.Clone()
+}
+
+// UnionSet is the Set adapter for Union.
+func (set *SyncSet[T]) UnionSet(other Set[T]) Set[T] {
+	return set.Union(other)
+}
+
+// IntersectionSet is the Set adapter for Intersection.
+func (set *SyncSet[T]) IntersectionSet(other Set[T]) Set[T] {
+	return set.Intersection(other)
+}
+
+// DifferenceSet is the Set adapter for Difference.
+func (set *SyncSet[T]) DifferenceSet(other Set[T]) Set[T] {
+	return set.Difference(other)
+}
+
+// SymetricDifferenceSet is the Set adapter for SymetricDifference.
+func (set *SyncSet[T]) SymetricDifferenceSet(other Set[T]) Set[T] {
+	return set.SymetricDifference(other)
+}
+
+// String implements the Stringer interface for SyncSet.
+func (set *SyncSet[T]) String() string {
+	set.mu.RLock()
+	defer set.mu.RUnlock()
+	return set.data.String()
+}
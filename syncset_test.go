@@ -0,0 +1,192 @@
+package intset
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/knakk/specs"
+)
+
+func TestSyncSetAddIfAbsent(t *testing.T) {
+	specs := specs.New(t)
+
+	set := Synchronized[int](NewBriggsSet(1000))
+	added := make([]int, 8)
+	var wg sync.WaitGroup
+	for g := range added {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				if set.AddIfAbsent(i) {
+					added[g]++
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	var total int
+	for _, n := range added {
+		total += n
+	}
+	specs.Expect(total, 1000)
+	specs.Expect(set.Size(), 1000)
+	specs.Expect(set.RemoveIfPresent(5), true)
+	specs.Expect(set.RemoveIfPresent(5), false)
+}
+
+func TestSyncSetSnapshot(t *testing.T) {
+	specs := specs.New(t)
+
+	set := Synchronized[int](NewHashSet(10)).Add(1, 2, 3)
+	snapshot := set.Snapshot()
+	set.Remove(1)
+
+	specs.Expect(snapshot.Contains(1, 2, 3), true)
+	specs.Expect(set.Contains(1), false)
+	_, isHashSet := snapshot.(*HashSet[int])
+	specs.Expect(isHashSet, true)
+}
+
+func TestSyncSetUpdate(t *testing.T) {
+	specs := specs.New(t)
+
+	set := Synchronized[int](NewSliceSet(100))
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				set.Update(func(s Set[int]) {
+					// move the smallest integer not in the set into it
+					for j := 0; j <= 100; j++ {
+						if !s.Contains(j) {
+							s.Insert(j)
+							return
+						}
+					}
+				})
+			}
+		}()
+	}
+	wg.Wait()
+
+	specs.Expect(set.Size(), 101)
+	set.View(func(s Set[int]) {
+		specs.Expect(s.Contains(0, 50, 100), true)
+	})
+}
+
+func TestSyncSetAlgebra(t *testing.T) {
+	specs := specs.New(t)
+
+	setA := Synchronized[int](NewBitSet(10)).Add(1, 2, 4)
+	setB := Synchronized[int](NewSliceSet(10)).Add(1, 2, 3)
+	setC := NewHashSet(10).Add(1, 2)
+
+	specs.Expect(setA.Union(setB).Equal(NewHashSet(10).Add(1, 2, 3, 4)), true)
+	specs.Expect(setA.Intersection(setB).Equal(setC), true)
+	specs.Expect(setA.Difference(setB).Equal(NewHashSet(10).Add(4)), true)
+	specs.Expect(setA.SymetricDifference(setB).Equal(NewHashSet(10).Add(3, 4)), true)
+	specs.Expect(setA.SupersetOf(setC), true)
+	specs.Expect(setC.SubsetOf(setB), true)
+	specs.Expect(setA.Equal(setA), true)
+	specs.Expect(setA.Clone().Equal(setA), true)
+	specs.Expect(setA.Clear().Size(), 0)
+}
+
+func TestSyncSetInPlace(t *testing.T) {
+	specs := specs.New(t)
+
+	setA := Synchronized[int](NewBitSet(10)).Add(1, 2, 4)
+	setB := Synchronized[int](NewSliceSet(10)).Add(1, 2, 3)
+
+	specs.Expect(setA.Clone().UnionWith(setB).Equal(NewHashSet(10).Add(1, 2, 3, 4)), true)
+	specs.Expect(setA.Clone().IntersectWith(setB).Equal(NewHashSet(10).Add(1, 2)), true)
+	specs.Expect(setA.Clone().DifferenceWith(setB).Equal(NewHashSet(10).Add(4)), true)
+	specs.Expect(setA.Clone().SymmetricDifferenceWith(setB).Equal(NewHashSet(10).Add(3, 4)), true)
+	specs.Expect(setA.UnionWith(setA).Size(), 3)
+	specs.Expect(setA.SymmetricDifferenceWith(setA).Size(), 0)
+}
+
+func TestSyncSetInPlaceAllocations(t *testing.T) {
+	specs := specs.New(t)
+
+	setA := Synchronized[int](NewBitSet(0).AddRange(0, 1000))
+	setB := Synchronized[int](NewBitSet(0).AddRange(500, 2000))
+	empty := NewBitSet(0)
+
+	allocs := testing.AllocsPerRun(10, func() {
+		setA.UnionWith(setB)
+		setA.IntersectWith(setB)
+		setA.DifferenceWith(empty)
+	})
+	specs.Expect(allocs, 0.0)
+	specs.Expect(setA.Equal(setB), true)
+}
+
+func TestSyncSetNoDeadlock(t *testing.T) {
+	setA := Synchronized[int](NewBitSet(0))
+	setB := Synchronized[int](NewBitSet(0))
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				setA.Add(i)
+				setA.Union(setB)
+				setA.UnionWith(setB)
+				setA.SubsetOf(setB)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				setB.Add(i)
+				setB.Intersection(setA)
+				setB.DifferenceWith(setA)
+				setB.Equal(setA)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// ranker is implemented by the set types with Rank and Select.
+type ranker interface {
+	Rank(int) int
+	Select(int) (int, bool)
+}
+
+func TestSyncSetConcurrentRank(t *testing.T) {
+	specs := specs.New(t)
+
+	for _, data := range []IntSet{NewBitSet(0), NewIntervalSet(0), NewRoaringSet(0), NewSliceSet(0)} {
+		set := Synchronized(data)
+		var wg sync.WaitGroup
+		for g := 0; g < 4; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 200; i++ {
+					if g == 0 {
+						set.Add(i * 3)
+						continue
+					}
+					set.View(func(s IntSet) {
+						if k := s.(ranker).Rank(i*3) - 1; k >= 0 {
+							v, _ := s.(ranker).Select(k)
+							specs.Expect(v, i*3)
+						}
+					})
+				}
+			}()
+		}
+		wg.Wait()
+		specs.Expect(data.(ranker).Rank(597), 200)
+	}
+}
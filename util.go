@@ -1,8 +1,114 @@
 package intset
 
+import (
+	"iter"
+	"math/bits"
+	"sync/atomic"
+)
+
 func max(a, b int) int {
 	if a > b {
 		return a
 	}
 	return b
 }
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// rankIndex holds the cumulative counts used by the Rank and Select methods
+// of a set. It is built lazily by the first query, and reset when the set is
+// modified. It is stored atomically, so that concurrent readers, such as those
+// holding the read lock of a SyncSet, may build it at the same time.
+type rankIndex struct {
+	counts atomic.Pointer[[]int]
+}
+
+// reset discards the index. It is called when the set is modified.
+func (r *rankIndex) reset() {
+	r.counts.Store(nil)
+}
+
+// get returns the index, calling build to build it unless it is already
+// built.
+func (r *rankIndex) get(build func() []int) []int {
+	if counts := r.counts.Load(); counts != nil {
+		return *counts
+	}
+	counts := build()
+	r.counts.Store(&counts)
+	return counts
+}
+
+// selectBit returns the position of the k-th set bit in w, counting from 0.
+func selectBit(w uint64, k int) int {
+	for ; k > 0; k-- {
+		w &= w - 1
+	}
+	return bits.TrailingZeros64(w)
+}
+
+// rangeMask returns the mask of the bits of word k which are in the range from
+// lo to hi, inclusive. Word k holds the bits from k*64 to k*64+63, also for
+// negative k.
+func rangeMask(k, lo, hi int) uint64 {
+	mask := ^uint64(0)
+	if k == lo>>6 {
+		mask &= ^uint64(0) << uint(lo&63)
+	}
+	if k == hi>>6 {
+		mask &= ^uint64(0) >> uint(63-hi&63)
+	}
+	return mask
+}
+
+// The following functions find integers in a sequence in linear time, for the
+// set types which don't keep their integers in order.
+
+func minOf[T Integer](values iter.Seq[T]) (T, bool) {
+	var m T
+	var found bool
+	for i := range values {
+		if !found || i < m {
+			m, found = i, true
+		}
+	}
+	return m, found
+}
+
+func maxOf[T Integer](values iter.Seq[T]) (T, bool) {
+	var m T
+	var found bool
+	for i := range values {
+		if !found || i > m {
+			m, found = i, true
+		}
+	}
+	return m, found
+}
+
+func nextAfter[T Integer](values iter.Seq[T], v T) (T, bool) {
+	var m T
+	var found bool
+	for i := range values {
+		if i > v && (!found || i < m) {
+			m, found = i, true
+		}
+	}
+	return m, found
+}
+
+func prevBefore[T Integer](values iter.Seq[T], v T) (T, bool) {
+	var m T
+	var found bool
+	for i := range values {
+		if i < v && (!found || i > m) {
+			m, found = i, true
+		}
+	}
+	return m, found
+}